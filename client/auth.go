@@ -0,0 +1,80 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Adds credentials to the requests made to the RDepot API
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// HTTP basic authentication. When Username is empty the token is expected
+// to have the form 'username:token'.
+type BasicAuth struct {
+	Username string
+	Token    string
+}
+
+func (a BasicAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Basic "+basicAuth(a.Username, a.Token))
+	return nil
+}
+
+func basicAuth(username string, token string) string {
+	var auth string
+	if username == "" {
+		auth = token
+	} else {
+		auth = username + ":" + token
+	}
+	return base64.StdEncoding.EncodeToString([]byte(auth))
+}
+
+// Bearer token authentication (RFC 6750), e.g. with an access token of an
+// identity provider
+type BearerAuth struct {
+	Token string
+}
+
+func (a BearerAuth) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
+
+// An authenticator created when the first request is made, so that no
+// keyring is opened or passphrase asked for by commands that do not need it
+type LazyAuth struct {
+	New func() (Authenticator, error)
+
+	once sync.Once
+	auth Authenticator
+	err  error
+}
+
+func (a *LazyAuth) Authenticate(req *http.Request) error {
+	a.once.Do(func() {
+		a.auth, a.err = a.New()
+	})
+	if a.err != nil {
+		return fmt.Errorf("could not get credentials: %w", a.err)
+	}
+	return a.auth.Authenticate(req)
+}
@@ -0,0 +1,183 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type memoryCache struct {
+	token *OIDCToken
+	saves int
+}
+
+func (c *memoryCache) Load() (*OIDCToken, error) {
+	return c.token, nil
+}
+
+func (c *memoryCache) Save(token *OIDCToken) error {
+	c.token = token
+	c.saves++
+	return nil
+}
+
+// A fake identity provider issuing access tokens numbered by the token
+// requests it received
+func identityProvider(t *testing.T) *httptest.Server {
+	var polls, issued int
+
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/realms/rdepot/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(rw).Encode(map[string]string{
+			"token_endpoint":                server.URL + "/token",
+			"device_authorization_endpoint": server.URL + "/device",
+		})
+	})
+	mux.HandleFunc("/device", func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "rdepot-cli", req.FormValue("client_id"))
+		expectEqual(t, "openid offline_access", req.FormValue("scope"))
+		rw.Write([]byte(`{"device_code": "devicecode", "user_code": "ABCD-EFGH", "verification_uri": "https://sso.example.com/device", "expires_in": 60, "interval": 0}`))
+	})
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "rdepot-cli", req.FormValue("client_id"))
+		switch req.FormValue("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			expectEqual(t, "devicecode", req.FormValue("device_code"))
+			if polls++; polls < 2 {
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(`{"error": "authorization_pending"}`))
+				return
+			}
+		case "refresh_token":
+			if req.FormValue("refresh_token") != "refreshtoken" {
+				rw.WriteHeader(http.StatusBadRequest)
+				rw.Write([]byte(`{"error": "invalid_grant", "error_description": "Token is not active"}`))
+				return
+			}
+		default:
+			t.Errorf("Unexpected grant type %s", req.FormValue("grant_type"))
+		}
+		issued++
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"access_token":  "accesstoken" + string(rune('0'+issued)),
+			"refresh_token": "refreshtoken",
+			"token_type":    "Bearer",
+			"expires_in":    300,
+		})
+	})
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestOIDCDeviceLogin(t *testing.T) {
+	server := identityProvider(t)
+	defer server.Close()
+
+	cache := &memoryCache{}
+	auth := &OIDCAuth{
+		Issuer:     server.URL + "/realms/rdepot",
+		ClientID:   "rdepot-cli",
+		Scopes:     []string{"openid", "offline_access"},
+		HTTPClient: server.Client(),
+		Cache:      cache,
+	}
+
+	req, _ := http.NewRequest("GET", "https://rdepot.example.com", nil)
+	if err := auth.Authenticate(req); !errors.Is(err, ErrLoginRequired) {
+		t.Fatalf("Expected ErrLoginRequired, got %v", err)
+	}
+
+	authorization, err := auth.StartDeviceLogin(context.Background())
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, "ABCD-EFGH", authorization.UserCode)
+
+	defer func(interval time.Duration) { defaultDeviceInterval = interval }(defaultDeviceInterval)
+	defaultDeviceInterval = time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	token, err := auth.WaitForDeviceToken(ctx, authorization)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, "accesstoken1", token.AccessToken)
+	expectEqual(t, 1, cache.saves)
+
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, "Bearer accesstoken1", req.Header.Get("Authorization"))
+}
+
+func TestOIDCRefresh(t *testing.T) {
+	server := identityProvider(t)
+	defer server.Close()
+
+	cache := &memoryCache{token: &OIDCToken{AccessToken: "expired", RefreshToken: "refreshtoken", Expiry: time.Now().Add(-time.Minute)}}
+	auth := &OIDCAuth{Issuer: server.URL + "/realms/rdepot", ClientID: "rdepot-cli", HTTPClient: server.Client(), Cache: cache}
+
+	req, _ := http.NewRequest("GET", "https://rdepot.example.com", nil)
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, "Bearer accesstoken1", req.Header.Get("Authorization"))
+	expectEqual(t, 1, cache.saves)
+	expectEqual(t, "refreshtoken", cache.token.RefreshToken)
+
+	// the cached token is valid now
+	if err := auth.Authenticate(req); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, 1, cache.saves)
+
+	cache = &memoryCache{token: &OIDCToken{AccessToken: "expired", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Minute)}}
+	auth = &OIDCAuth{Issuer: server.URL + "/realms/rdepot", ClientID: "rdepot-cli", HTTPClient: server.Client(), Cache: cache}
+	if err := auth.Authenticate(req); !errors.Is(err, ErrLoginRequired) {
+		t.Errorf("Expected ErrLoginRequired, got %v", err)
+	}
+}
+
+func TestAuthenticators(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://rdepot.example.com", nil)
+
+	BearerAuth{Token: "accesstoken"}.Authenticate(req)
+	expectEqual(t, "Bearer accesstoken", req.Header.Get("Authorization"))
+
+	BasicAuth{Username: "einstein", Token: "validtoken"}.Authenticate(req)
+	if username, token, ok := req.BasicAuth(); !ok || username != "einstein" || token != "validtoken" {
+		t.Errorf("Expected basic auth einstein:validtoken, got %s:%s", username, token)
+	}
+
+	var calls int
+	lazy := &LazyAuth{New: func() (Authenticator, error) {
+		calls++
+		return BearerAuth{Token: "lazytoken"}, nil
+	}}
+	for i := 0; i < 2; i++ {
+		if err := lazy.Authenticate(req); err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+	}
+	expectEqual(t, "Bearer lazytoken", req.Header.Get("Authorization"))
+	expectEqual(t, 1, calls)
+}
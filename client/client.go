@@ -0,0 +1,267 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client implements a client for the RDepot v2 manager API.
+//
+//	c := client.New(
+//		client.WithBaseURL("https://rdepot.example.com"),
+//		client.WithBasicAuth("einstein", token),
+//		client.WithTechnology("r"),
+//	)
+//	repos, err := c.ListRepositories(ctx, "")
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Configuration of the rdepot command line, see NewFromConfig
+type RDepotConfig struct {
+	Host       string
+	Token      string
+	Username   string
+	Technology string
+	// Used when Token is empty, e.g. to read a stored token
+	Credentials CredentialSource
+}
+
+// Provides the username and token for basic authentication when the first
+// request is made, see WithCredentialSource
+type CredentialSource func() (username string, token string, err error)
+
+// A client for the RDepot v2 manager API. Create one with New and configure
+// it with options, a Client is safe for concurrent use. Every call takes a
+// context that cancels the underlying requests when it is done.
+type Client struct {
+	baseURL    string
+	auth       Authenticator
+	technology string
+	userAgent  string
+	httpClient *http.Client
+	logger     *log.Logger
+
+	pageSize        int
+	pageConcurrency int
+}
+
+type Option func(*Client)
+
+// Base URL of the RDepot server, e.g. https://rdepot.example.com
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// Authenticate with HTTP basic authentication. When username is empty the
+// token is expected to have the form 'username:token'.
+func WithBasicAuth(username string, token string) Option {
+	return func(c *Client) {
+		c.auth = BasicAuth{Username: username, Token: token}
+	}
+}
+
+// Authenticate with HTTP basic authentication using the username and token
+// of source, which is only called once the first request is made
+func WithCredentialSource(source CredentialSource) Option {
+	return WithAuthenticator(&LazyAuth{New: func() (Authenticator, error) {
+		username, token, err := source()
+		return BasicAuth{Username: username, Token: token}, err
+	}})
+}
+
+// Authenticate with a bearer token, e.g. an access token of an identity
+// provider
+func WithBearerToken(token string) Option {
+	return WithAuthenticator(BearerAuth{Token: token})
+}
+
+// Authenticate requests with auth, see BasicAuth, BearerAuth, LazyAuth and
+// OIDCAuth
+func WithAuthenticator(auth Authenticator) Option {
+	return func(c *Client) {
+		c.auth = auth
+	}
+}
+
+// Technology ('r', 'python' or 'all') of the resources the client manages
+func WithTechnology(technology string) Option {
+	return func(c *Client) {
+		c.technology = technology
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Log every request and the response status to logger
+func WithLogger(logger *log.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// Number of items requested per page when listing resources
+func WithPageSize(size int) Option {
+	return func(c *Client) {
+		if size > 0 {
+			c.pageSize = size
+		}
+	}
+}
+
+// Number of pages fetched concurrently when listing resources
+func WithPageConcurrency(concurrency int) Option {
+	return func(c *Client) {
+		if concurrency > 0 {
+			c.pageConcurrency = concurrency
+		}
+	}
+}
+
+func New(opts ...Option) *Client {
+	c := &Client{
+		baseURL:    "http://localhost",
+		technology: "r",
+		userAgent:  "rdepot-cli",
+		httpClient: DefaultClient(),
+		logger:     log.New(io.Discard, "", 0),
+
+		pageSize:        DefaultPageSize,
+		pageConcurrency: DefaultPageConcurrency,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Create a client from the command line configuration, opts are applied last
+func NewFromConfig(cfg RDepotConfig, opts ...Option) *Client {
+	auth := WithBasicAuth(cfg.Username, cfg.Token)
+	if cfg.Token == "" && cfg.Credentials != nil {
+		auth = WithCredentialSource(func() (string, string, error) {
+			username, token, err := cfg.Credentials()
+			if cfg.Username != "" {
+				username = cfg.Username
+			}
+			return username, token, err
+		})
+	}
+	return New(append([]Option{
+		WithBaseURL(cfg.Host),
+		auth,
+		WithTechnology(cfg.Technology),
+	}, opts...)...)
+}
+
+// HTTP client retrying transient failures with the default retry policy
+func DefaultClient() *http.Client {
+	return &http.Client{Transport: NewRetryTransport(http.DefaultTransport)}
+}
+
+func (c *Client) Technology() string {
+	return c.technology
+}
+
+// Create an authenticated request for a path of the RDepot API
+func (c *Client) newRequest(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// Check that the server accepts the credentials of the client
+func (c *Client) CheckCredentials(ctx context.Context) error {
+	req, err := c.newRequest(ctx, "GET", "/api/v2/manager/repositories?page=0&size=1", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Send a request and check that the response has one of the expected status
+// codes, any other response is turned into an APIError
+func (c *Client) do(req *http.Request, expected ...int) (*http.Response, error) {
+	start := time.Now()
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		c.logger.Printf("%s %s: %v", req.Method, req.URL.Redacted(), err)
+		return nil, err
+	}
+	c.logger.Printf("%s %s: %s (%s)", req.Method, req.URL.Redacted(), res.Status, time.Since(start).Round(time.Millisecond))
+
+	for _, code := range expected {
+		if res.StatusCode == code {
+			return res, nil
+		}
+	}
+	defer res.Body.Close()
+	return nil, newAPIError(res)
+}
+
+// Pause for interval, or less when ctx is done first
+func sleep(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// API path prefix for a technology
+func technologyToPath(s string) (string, error) {
+	switch s {
+	case "r":
+		return "r/", nil
+	case "python":
+		return "python/", nil
+	case "all":
+		return "", nil
+	default:
+		return "", fmt.Errorf("undefined technology %s", s)
+	}
+}
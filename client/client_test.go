@@ -0,0 +1,127 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "/api/v2/manager/python/repositories", req.URL.Path)
+		expectEqual(t, "rdepot-test/1.0", req.Header.Get("User-Agent"))
+		expectEqual(t, "application/json", req.Header.Get("Accept"))
+		if username, token, ok := req.BasicAuth(); !ok || username != "einstein" || token != "validtoken" {
+			t.Errorf("Expected basic auth einstein:validtoken, got %s:%s", username, token)
+		}
+		rw.Write(repositoriesBody)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	c := New(
+		WithBaseURL(server.URL),
+		WithBasicAuth("einstein", "validtoken"),
+		WithTechnology("python"),
+		WithUserAgent("rdepot-test/1.0"),
+		WithHTTPClient(server.Client()),
+		WithLogger(log.New(&logs, "", 0)),
+	)
+
+	if _, err := c.ListRepositories(context.Background(), ""); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if !strings.Contains(logs.String(), "GET "+server.URL+"/api/v2/manager/python/repositories") {
+		t.Errorf("Expected request to be logged, got %q", logs.String())
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	c := NewFromConfig(RDepotConfig{Host: "https://rdepot.example.com", Token: "einstein:validtoken", Technology: "all"})
+	expectEqual(t, "https://rdepot.example.com", c.baseURL)
+	expectEqual(t, "all", c.Technology())
+	expectEqual(t, BasicAuth{Token: "einstein:validtoken"}, c.auth)
+}
+
+func TestContextCancellation(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Write(repositoriesBody)
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.ListRepositories(ctx, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", err)
+	}
+	expectEqual(t, 0, requests)
+}
+
+func TestCredentialSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if username, token, ok := req.BasicAuth(); !ok || username != "einstein" || token != "storedtoken" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rw.Write(repositoriesBody)
+	}))
+	defer server.Close()
+
+	var calls int
+	c := NewFromConfig(RDepotConfig{
+		Host:       server.URL,
+		Username:   "einstein",
+		Technology: "r",
+		Credentials: func() (string, string, error) {
+			calls++
+			return "", "storedtoken", nil
+		},
+	}, WithHTTPClient(server.Client()))
+
+	expectEqual(t, 0, calls)
+	for i := 0; i < 2; i++ {
+		if err := c.CheckCredentials(context.Background()); err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+	}
+	expectEqual(t, 1, calls)
+
+	failure := errors.New("keyring locked")
+	c = New(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithCredentialSource(func() (string, string, error) {
+		return "", "", failure
+	}))
+	if err := c.CheckCredentials(context.Background()); !errors.Is(err, failure) {
+		t.Errorf("Expected %s, got %v", failure, err)
+	}
+
+	c = New(WithBaseURL(server.URL), WithHTTPClient(server.Client()), WithBasicAuth("einstein", "wrongtoken"))
+	var apiErr *APIError
+	if err := c.CheckCredentials(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+// A package archive that was downloaded and verified
+type Download struct {
+	File     string `json:"file"`
+	URL      string `json:"url"`
+	Checksum string `json:"checksum"`
+}
+
+func (d Download) Header(wide bool) []string {
+	header := []string{"FILE", "CHECKSUM"}
+	if wide {
+		header = append(header, "URL")
+	}
+	return header
+}
+
+func (d Download) Row(wide bool) []string {
+	row := []string{d.File, d.Checksum}
+	if wide {
+		row = append(row, d.URL)
+	}
+	return row
+}
+
+// The archive did not match the checksum of the package
+type ChecksumError struct {
+	URL       string
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch for %s: expected %s, got %s", e.Algorithm, e.URL, e.Expected, e.Actual)
+}
+
+// Checksum of a package archive: the MD5 sum of R packages or the hash of
+// Python packages with the hash method of their repository
+type checksum struct {
+	algorithm string
+	new       func() hash.Hash
+	sum       string
+}
+
+func (c checksum) String() string {
+	return c.algorithm + ":" + c.sum
+}
+
+// Hash algorithms by the names used in hash prefixes and hash methods of
+// repositories, lower case and without separators
+var hashAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha224": sha256.New224,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+func newChecksum(algorithm string, sum string) (checksum, error) {
+	algorithm = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(algorithm))
+	h, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return checksum{}, fmt.Errorf("unsupported hash algorithm %s", algorithm)
+	}
+	return checksum{algorithm: algorithm, new: h, sum: strings.ToLower(sum)}, nil
+}
+
+// Download the archive of a package into dir. It is fetched from the
+// publication URI of its repository, or from the download endpoint of the
+// manager when the repository does not serve it, and only written to dir
+// once it matches the checksum of the package.
+func (c *Client) DownloadPackage(ctx context.Context, pkg model.GenericPackage, dir string) (Download, error) {
+	var download Download
+
+	p := pkg.GetPackage()
+	technology := strings.ToLower(p.Technology)
+	file, err := archiveName(p, technology)
+	if err != nil {
+		return download, err
+	}
+	if p.Repository.PublicationUri == "" || (technology == "python" && p.Repository.HashMethod == "") {
+		if repo, err := c.GetRepository(ctx, p.Repository.Name); err == nil {
+			p.Repository = repo
+		}
+	}
+
+	sum, err := c.packageChecksum(ctx, pkg, technology, p.Repository.HashMethod)
+	if err != nil {
+		return download, err
+	}
+
+	urls := c.publishedArchiveURLs(ctx, p, technology, file)
+	urls = append(urls, c.baseURL+fmt.Sprintf("/api/v2/manager/%s/packages/%d/download/%s", technology, p.Id, url.PathEscape(file)))
+
+	var lastErr error
+	for i, u := range urls {
+		// only the manager endpoint needs credentials
+		download, lastErr = c.downloadArchive(ctx, u, i == len(urls)-1, sum, filepath.Join(dir, file))
+		var checksumErr *ChecksumError
+		if lastErr == nil || errors.As(lastErr, &checksumErr) || ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr != nil {
+		return download, fmt.Errorf("could not download %s: %w", pkg.Summary(), lastErr)
+	}
+	return download, nil
+}
+
+// Checksum of the package, the algorithm of a Python hash is taken from its
+// prefix, e.g. 'sha256=...', or otherwise from the hash method of the
+// repository
+func (c *Client) packageChecksum(ctx context.Context, pkg model.GenericPackage, technology string, hashMethod string) (checksum, error) {
+	id := pkg.GetId()
+	switch technology {
+	case "r":
+		rpkg, ok := pkg.(model.RPackage)
+		if !ok {
+			var err error
+			if rpkg, err = getEntity[model.RPackage](ctx, c, fmt.Sprintf("/api/v2/manager/r/packages/%d", id)); err != nil {
+				return checksum{}, err
+			}
+		}
+		if rpkg.Md5sum == "" {
+			return checksum{}, fmt.Errorf("package %s has no MD5 sum to verify the download", pkg.Summary())
+		}
+		return newChecksum("md5", rpkg.Md5sum)
+	case "python":
+		ppkg, ok := pkg.(model.PythonPackage)
+		if !ok {
+			var err error
+			if ppkg, err = getEntity[model.PythonPackage](ctx, c, fmt.Sprintf("/api/v2/manager/python/packages/%d", id)); err != nil {
+				return checksum{}, err
+			}
+		}
+		if hashMethod == "" {
+			hashMethod = ppkg.Repository.HashMethod
+		}
+		sum := ppkg.Hash
+		if algorithm, value, ok := strings.Cut(sum, "="); ok {
+			hashMethod, sum = algorithm, value
+		} else if algorithm, value, ok := strings.Cut(sum, ":"); ok {
+			hashMethod, sum = algorithm, value
+		}
+		if sum == "" {
+			return checksum{}, fmt.Errorf("package %s has no hash to verify the download", pkg.Summary())
+		}
+		if hashMethod == "" {
+			return checksum{}, fmt.Errorf("unknown hash method of repository %s to verify the download of %s", ppkg.Repository.Name, pkg.Summary())
+		}
+		return newChecksum(hashMethod, sum)
+	default:
+		return checksum{}, fmt.Errorf("undefined technology %s", technology)
+	}
+}
+
+// File name of the archive, as stored by the server
+func archiveName(pkg model.Package, technology string) (string, error) {
+	if pkg.Source != "" {
+		name := path.Base(filepath.ToSlash(pkg.Source))
+		if name == "." || name == ".." || name == "/" {
+			return "", fmt.Errorf("invalid source %s of package %s", pkg.Source, pkg.Summary())
+		}
+		return name, nil
+	}
+	if technology == "python" {
+		return pkg.Name + "-" + pkg.Version.CanonicalRep + ".tar.gz", nil
+	}
+	return pkg.Name + "_" + pkg.Version.CanonicalRep + ".tar.gz", nil
+}
+
+var (
+	hrefPattern      = regexp.MustCompile(`href="([^"]+)"`)
+	separatorPattern = regexp.MustCompile(`[-_.]+`)
+)
+
+// URLs of the archive in the published repository: the current and archived
+// locations of R packages, or the link on the simple index page (PEP 503) of
+// Python packages
+func (c *Client) publishedArchiveURLs(ctx context.Context, pkg model.Package, technology string, file string) []string {
+	uri := strings.TrimSuffix(pkg.Repository.PublicationUri, "/")
+	if uri == "" {
+		return nil
+	}
+
+	if technology == "r" {
+		return []string{
+			uri + "/src/contrib/" + url.PathEscape(file),
+			uri + "/src/contrib/Archive/" + url.PathEscape(pkg.Name) + "/" + url.PathEscape(file),
+		}
+	}
+
+	name := strings.ToLower(separatorPattern.ReplaceAllString(pkg.Name, "-"))
+	page, err := url.Parse(uri + "/simple/" + name + "/")
+	if err != nil {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", page.String(), nil)
+	if err != nil {
+		return nil
+	}
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return nil
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil
+	}
+
+	for _, match := range hrefPattern.FindAllStringSubmatch(string(body), -1) {
+		link, err := page.Parse(match[1])
+		if err != nil {
+			continue
+		}
+		link.Fragment = ""
+		if path.Base(link.Path) == file {
+			return []string{link.String()}
+		}
+	}
+	return nil
+}
+
+// Download an archive to a temporary file next to dest, which is renamed to
+// dest when it matches the checksum
+func (c *Client) downloadArchive(ctx context.Context, u string, authenticate bool, sum checksum, dest string) (Download, error) {
+	download := Download{File: dest, URL: u, Checksum: sum.String()}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return download, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if authenticate && c.auth != nil {
+		if err := c.auth.Authenticate(req); err != nil {
+			return download, err
+		}
+	}
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return download, err
+	}
+	defer res.Body.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return download, err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sum.new()
+	_, err = io.Copy(io.MultiWriter(tmp, h), res.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return download, err
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != sum.sum {
+		return download, &ChecksumError{URL: u, Algorithm: sum.algorithm, Expected: sum.sum, Actual: actual}
+	}
+	return download, os.Rename(tmp.Name(), dest)
+}
@@ -0,0 +1,182 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func TestDownloadPackage(t *testing.T) {
+	archive := []byte("archive")
+	md5sum := md5.Sum(archive)
+	sha256sum := sha256.Sum256(archive)
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/repo/testrepo1/src/contrib/Archive/accrued/accrued_1.2.tar.gz",
+			"/repo/testrepo2/packages/py_pkg-1.0.tar.gz",
+			"/api/v2/manager/r/packages/3/download/accrued_1.3.tar.gz":
+			authorization = req.Header.Get("Authorization")
+			rw.Write(archive)
+		case "/repo/testrepo2/simple/py-pkg/":
+			rw.Write([]byte(`<a href="../../packages/py_pkg-1.0.tar.gz#sha256=abc">py_pkg-1.0.tar.gz</a>`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("user", "token"), WithHTTPClient(server.Client()))
+	version, _ := model.CanonicalVersion("1.2")
+	pythonVersion, _ := model.CanonicalVersion("1.0")
+
+	rpkg := model.RPackage{
+		Package: model.Package{
+			Id:         1,
+			Name:       "accrued",
+			Version:    *version,
+			Technology: "R",
+			Source:     "/opt/rdepot/repositories/2/accrued_1.2.tar.gz",
+			Repository: model.Repository{Name: "testrepo1", PublicationUri: server.URL + "/repo/testrepo1"},
+		},
+		Md5sum: hex.EncodeToString(md5sum[:]),
+	}
+	ppkg := model.PythonPackage{
+		Package: model.Package{
+			Id:         2,
+			Name:       "py_pkg",
+			Version:    *pythonVersion,
+			Technology: "Python",
+			Source:     "/opt/rdepot/repositories/3/py_pkg-1.0.tar.gz",
+			Repository: model.Repository{Name: "testrepo2", PublicationUri: server.URL + "/repo/testrepo2/", HashMethod: "SHA256"},
+		},
+		Hash: hex.EncodeToString(sha256sum[:]),
+	}
+	md5Hashed := ppkg
+	md5Hashed.Hash = "md5=" + rpkg.Md5sum
+	md5Hashed.Repository.HashMethod = ""
+	unpublished := rpkg
+	unpublished.Id = 3
+	unpublished.Source = ""
+	newer, _ := model.CanonicalVersion("1.3")
+	unpublished.Version = *newer
+	unpublished.Repository.PublicationUri = server.URL + "/repo/unpublished"
+
+	var tests = []struct {
+		pkg           model.GenericPackage
+		url           string
+		authorization bool
+	}{
+		{pkg: rpkg, url: "/repo/testrepo1/src/contrib/Archive/accrued/accrued_1.2.tar.gz"},
+		{pkg: ppkg, url: "/repo/testrepo2/packages/py_pkg-1.0.tar.gz"},
+		{pkg: md5Hashed, url: "/repo/testrepo2/packages/py_pkg-1.0.tar.gz"},
+		{pkg: unpublished, url: "/api/v2/manager/r/packages/3/download/accrued_1.3.tar.gz", authorization: true},
+	}
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		download, err := c.DownloadPackage(context.Background(), test.pkg, dir)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		expectEqual(t, server.URL+test.url, download.URL)
+		expectEqual(t, test.authorization, authorization != "")
+
+		content, err := os.ReadFile(download.File)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		expectEqual(t, string(archive), string(content))
+		expectEqual(t, dir, filepath.Dir(download.File))
+	}
+}
+
+func TestDownloadPackageChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	version, _ := model.CanonicalVersion("1.2")
+	pkg := model.RPackage{
+		Package: model.Package{
+			Name:       "accrued",
+			Version:    *version,
+			Technology: "R",
+			Repository: model.Repository{Name: "testrepo1", PublicationUri: server.URL},
+		},
+		Md5sum: "d41d8cd98f00b204e9800998ecf8427e",
+	}
+
+	dir := t.TempDir()
+	var checksumErr *ChecksumError
+	if _, err := c.DownloadPackage(context.Background(), pkg, dir); !errors.As(err, &checksumErr) {
+		t.Fatalf("Expected ChecksumError, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected no files to be written, got %d", len(entries))
+	}
+}
+
+func TestDownloadPackageInvalid(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte("archive"))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	version, _ := model.CanonicalVersion("1.0")
+	pkg := func(source string, hash string, hashMethod string) model.PythonPackage {
+		return model.PythonPackage{
+			Package: model.Package{
+				Name:       "py_pkg",
+				Version:    *version,
+				Technology: "Python",
+				Source:     source,
+				Repository: model.Repository{Name: "testrepo2", PublicationUri: server.URL, HashMethod: hashMethod},
+			},
+			Hash: hash,
+		}
+	}
+
+	var tests = []model.PythonPackage{
+		pkg("/opt/rdepot/py_pkg-1.0.tar.gz", "crc32=abc", ""),
+		pkg("/opt/rdepot/py_pkg-1.0.tar.gz", "abc", "whirlpool"),
+		pkg("..", "abc", "SHA256"),
+		pkg("/", "abc", "SHA256"),
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		if _, err := c.DownloadPackage(context.Background(), test, dir); err == nil {
+			t.Errorf("Expected error for %s with hash %s", test.Source, test.Hash)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("Expected no files to be written, got %d", len(entries))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+// A single JSON-Patch (RFC 6902) operation
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Fetch a single entity from the v2 API
+func getEntity[C any](ctx context.Context, c *Client, path string) (C, error) {
+	var entity C
+
+	req, err := c.newRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return entity, err
+	}
+
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return entity, err
+	}
+	defer res.Body.Close()
+
+	return decodeEntity[C](res.Body)
+}
+
+// Apply a JSON-Patch to a single entity of the v2 API and return the result
+func patchEntity[C any](ctx context.Context, c *Client, path string, patch []PatchOperation) (C, error) {
+	var entity C
+
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return entity, err
+	}
+
+	if idempotentPatch(patch) {
+		ctx = Idempotent(ctx)
+	}
+
+	req, err := c.newRequest(ctx, "PATCH", path, bytes.NewBuffer(body))
+	if err != nil {
+		return entity, err
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return entity, err
+	}
+	defer res.Body.Close()
+
+	return decodeEntity[C](res.Body)
+}
+
+// A patch that only replaces or tests values has the same effect when it is
+// applied twice, so it can safely be retried
+func idempotentPatch(patch []PatchOperation) bool {
+	for _, op := range patch {
+		if op.Op != "replace" && op.Op != "test" {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeEntity[C any](r io.Reader) (C, error) {
+	var response model.EntityResponse[C]
+
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return response.Data, err
+	}
+	if err := response.Unmarshal(body); err != nil {
+		return response.Data, err
+	}
+	return response.Data, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+// An unsuccessful response of the RDepot API, decoded from the response
+// envelope when the server sent one
+type APIError struct {
+	StatusCode  int
+	Status      string
+	Code        int
+	Message     string
+	MessageCode string
+	Details     json.RawMessage
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("bad status: %s", e.Status)
+	}
+	msg := e.Message
+	if details := e.DetailMessages(); len(details) > 0 {
+		msg = strings.TrimSuffix(msg, ".") + ": " + strings.Join(details, "; ")
+	}
+	return msg
+}
+
+// Human readable messages found in the data of the error envelope, which
+// typically holds validation errors
+func (e *APIError) DetailMessages() []string {
+	var items []interface{}
+	if err := json.Unmarshal(e.Details, &items); err != nil {
+		var item interface{}
+		if err := json.Unmarshal(e.Details, &item); err != nil || item == nil {
+			return nil
+		}
+		items = []interface{}{item}
+	}
+
+	var messages []string
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			messages = append(messages, v)
+		case map[string]interface{}:
+			msg, _ := v["message"].(string)
+			if msg == "" {
+				continue
+			}
+			if field, _ := v["field"].(string); field != "" {
+				msg = field + ": " + msg
+			}
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// An entity looked up by name that does not exist
+type NotFoundError struct {
+	Kind string
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found: %s", e.Kind, e.Name)
+}
+
+// The package index of a repository cannot be fetched since the repository
+// has no valid publication URI
+type IndexError struct {
+	Repository string
+	Reason     string
+}
+
+func (e *IndexError) Error() string {
+	return fmt.Sprintf("cannot fetch the index of repository %s: %s", e.Repository, e.Reason)
+}
+
+// Build an APIError from an unexpected response, the body is consumed
+func newAPIError(res *http.Response) error {
+	apiErr := &APIError{StatusCode: res.StatusCode, Status: res.Status}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return apiErr
+	}
+
+	var envelope model.EntityResponse[json.RawMessage]
+	if err := envelope.Unmarshal(body); err == nil {
+		apiErr.Code = envelope.Code
+		apiErr.Message = envelope.Message
+		apiErr.MessageCode = envelope.MessageCode
+		apiErr.Details = envelope.Data
+	}
+	return apiErr
+}
@@ -0,0 +1,75 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+
+	var tests = []struct {
+		status      int
+		body        string
+		message     string
+		messageCode string
+	}{
+		{
+			status:      http.StatusNotFound,
+			body:        `{"status": "ERROR", "code": 404, "message": "Requested resource was not found.", "messageCode": "error.notfound", "data": null}`,
+			message:     "Requested resource was not found.",
+			messageCode: "error.notfound",
+		},
+		{
+			status:      http.StatusUnprocessableEntity,
+			body:        `{"status": "ERROR", "code": 422, "message": "Validation failed.", "messageCode": "error.validation", "data": [{"field": "name", "message": "Name is already taken."}, "Invalid publication URI."]}`,
+			message:     "Validation failed: name: Name is already taken.; Invalid publication URI.",
+			messageCode: "error.validation",
+		},
+		{
+			status:      http.StatusBadGateway,
+			body:        `<html>Bad Gateway</html>`,
+			message:     "bad status: 502 Bad Gateway",
+			messageCode: "",
+		},
+	}
+
+	for _, test := range tests {
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(test.status)
+			rw.Write([]byte(test.body))
+		}))
+		defer server.Close()
+
+		c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+		_, err := c.ListRepositories(context.Background(), "")
+		err = fmt.Errorf("wrapped: %w", err)
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected APIError, got %T", err)
+		}
+		expectEqual(t, test.status, apiErr.StatusCode)
+		expectEqual(t, test.messageCode, apiErr.MessageCode)
+		expectEqual(t, test.message, apiErr.Error())
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Returned when there is no valid OIDC token and it cannot be refreshed
+var ErrLoginRequired = errors.New("login required")
+
+// An access token is refreshed this long before it expires
+const tokenExpiryDelta = 30 * time.Second
+
+// Polling interval of the device flow when the provider does not set one
+var defaultDeviceInterval = 5 * time.Second
+
+// Tokens issued by an OpenID Connect provider
+type OIDCToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+func (t *OIDCToken) valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(t.Expiry))
+}
+
+// Keeps OIDC tokens between runs
+type TokenCache interface {
+	// Returns nil without error when no token is cached
+	Load() (*OIDCToken, error)
+	Save(token *OIDCToken) error
+}
+
+// Authentication with access tokens of an OpenID Connect provider such as
+// Keycloak. Tokens are obtained with the device authorization flow
+// (RFC 8628), see StartDeviceLogin, cached and refreshed with the refresh
+// token when they expire.
+type OIDCAuth struct {
+	Issuer     string
+	ClientID   string
+	Scopes     []string
+	HTTPClient *http.Client
+	Cache      TokenCache
+
+	mu     sync.Mutex
+	loaded bool
+	token  *OIDCToken
+
+	discoverMu sync.Mutex
+	endpoints  *oidcEndpoints
+}
+
+type oidcEndpoints struct {
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+}
+
+// Response of the device authorization endpoint
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval,omitempty"`
+}
+
+// Error response of the token endpoint (RFC 6749 section 5.2)
+type tokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *tokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+func (a *OIDCAuth) Authenticate(req *http.Request) error {
+	token, err := a.Token(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return nil
+}
+
+// A valid token from memory or the cache, refreshed when it expired
+func (a *OIDCAuth) Token(ctx context.Context) (*OIDCToken, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.loaded && a.Cache != nil {
+		token, err := a.Cache.Load()
+		if err != nil {
+			return nil, err
+		}
+		a.token = token
+	}
+	a.loaded = true
+
+	if a.token.valid() {
+		return a.token, nil
+	}
+	if a.token == nil || a.token.RefreshToken == "" {
+		return nil, ErrLoginRequired
+	}
+
+	token, err := a.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {a.token.RefreshToken},
+	})
+	var tokenErr *tokenError
+	if errors.As(err, &tokenErr) && tokenErr.Code == "invalid_grant" {
+		return nil, fmt.Errorf("%w: the session expired (%s)", ErrLoginRequired, tokenErr)
+	} else if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = a.token.RefreshToken
+	}
+	return token, a.setToken(token)
+}
+
+func (a *OIDCAuth) setToken(token *OIDCToken) error {
+	a.token = token
+	a.loaded = true
+	if a.Cache != nil {
+		return a.Cache.Save(token)
+	}
+	return nil
+}
+
+// Start the device authorization flow. Show the user code and verification
+// URI of the result to the user, then wait for the user to log in with
+// WaitForDeviceToken.
+func (a *OIDCAuth) StartDeviceLogin(ctx context.Context) (DeviceAuthorization, error) {
+	var authorization DeviceAuthorization
+
+	endpoints, err := a.discover(ctx)
+	if err != nil {
+		return authorization, err
+	}
+	if endpoints.DeviceAuthorizationEndpoint == "" {
+		return authorization, fmt.Errorf("identity provider %s does not support the device authorization flow", a.Issuer)
+	}
+
+	res, err := a.postForm(ctx, endpoints.DeviceAuthorizationEndpoint, url.Values{
+		"client_id": {a.ClientID},
+		"scope":     {strings.Join(a.Scopes, " ")},
+	})
+	if err != nil {
+		return authorization, err
+	}
+	defer res.Body.Close()
+
+	if err := decodeTokenResponse(res, &authorization); err != nil {
+		return authorization, err
+	}
+	return authorization, nil
+}
+
+// Poll the token endpoint until the user logged in, then cache the token
+func (a *OIDCAuth) WaitForDeviceToken(ctx context.Context, authorization DeviceAuthorization) (*OIDCToken, error) {
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+	if authorization.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(authorization.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			return nil, fmt.Errorf("stopped waiting for the login: %w", err)
+		}
+
+		token, err := a.requestToken(ctx, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {authorization.DeviceCode},
+		})
+		var tokenErr *tokenError
+		switch {
+		case err == nil:
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			return token, a.setToken(token)
+		case errors.As(err, &tokenErr) && tokenErr.Code == "authorization_pending":
+		case errors.As(err, &tokenErr) && tokenErr.Code == "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Read the endpoints from the discovery document of the issuer
+func (a *OIDCAuth) discover(ctx context.Context) (*oidcEndpoints, error) {
+	a.discoverMu.Lock()
+	defer a.discoverMu.Unlock()
+
+	if a.endpoints != nil {
+		return a.endpoints, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(a.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := a.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not discover identity provider %s: %s", a.Issuer, res.Status)
+	}
+
+	var endpoints oidcEndpoints
+	if err := json.NewDecoder(res.Body).Decode(&endpoints); err != nil {
+		return nil, fmt.Errorf("invalid discovery document of identity provider %s: %w", a.Issuer, err)
+	}
+	if endpoints.TokenEndpoint == "" {
+		return nil, fmt.Errorf("identity provider %s has no token endpoint", a.Issuer)
+	}
+	a.endpoints = &endpoints
+	return a.endpoints, nil
+}
+
+func (a *OIDCAuth) requestToken(ctx context.Context, form url.Values) (*OIDCToken, error) {
+	endpoints, err := a.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form.Set("client_id", a.ClientID)
+	res, err := a.postForm(ctx, endpoints.TokenEndpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	var response struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := decodeTokenResponse(res, &response); err != nil {
+		return nil, err
+	}
+
+	token := &OIDCToken{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+		TokenType:    response.TokenType,
+	}
+	if response.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func (a *OIDCAuth) postForm(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return a.httpClient().Do(req)
+}
+
+func (a *OIDCAuth) httpClient() *http.Client {
+	if a.HTTPClient != nil {
+		return a.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// Decode a successful response into v or an OAuth error response into a
+// tokenError
+func decodeTokenResponse(res *http.Response, v interface{}) error {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		var tokenErr tokenError
+		if json.Unmarshal(body, &tokenErr) == nil && tokenErr.Code != "" {
+			return &tokenErr
+		}
+		return fmt.Errorf("bad status from identity provider: %s", res.Status)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -16,83 +16,81 @@ package client
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
 	"openanalytics.eu/rdepot/cli/model"
 )
 
-type RDepotConfig struct {
-	Host       string
-	Token      string
-	Username   string
-	Technology string
-}
-
-func DefaultClient() *http.Client {
-	return http.DefaultClient
-}
-
-func basicAuth(username string, token string) string {
-	var auth string
-	if username == "" {
-		auth = token
-	} else {
-		auth = username + ":" + token
-	}
-	return base64.StdEncoding.EncodeToString([]byte(auth))
-}
-
-func SoftDeletePackage(client *http.Client, cfg RDepotConfig, id int) error {
-
-	patchJson := []byte(`[{"op": "replace", "path": "/deleted", "value": true}]`)
-	if cfg.Technology != "python" && cfg.Technology != "r" {
+func (c *Client) SoftDeletePackage(ctx context.Context, id int) error {
+	if c.technology != "python" && c.technology != "r" {
 		return fmt.Errorf("invalid technology provided for deleting only Python and R are supported")
 	}
-	path, err := technologyToPath(cfg.Technology)
+	path, err := technologyToPath(c.technology)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("PATCH", cfg.Host+fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", id), bytes.NewBuffer(patchJson))
-	if err != nil {
-		return err
-	}
+	_, err = patchEntity[json.RawMessage](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", id), []PatchOperation{
+		{Op: "replace", Path: "/deleted", Value: true},
+	})
+	return err
+}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json-patch+json")
-	req.Header.Set("Authorization", "Basic "+basicAuth(cfg.Username, cfg.Token))
-	res, err := client.Do(req)
+// Undo the soft delete of a package
+func (c *Client) RestorePackage(ctx context.Context, pkg model.Package) error {
+	technology := strings.ToLower(pkg.Technology)
+	if technology != "python" && technology != "r" {
+		return fmt.Errorf("invalid technology provided for restoring only Python and R are supported")
+	}
+	path, err := technologyToPath(technology)
 	if err != nil {
 		return err
 	}
 
-	defer res.Body.Close()
+	_, err = patchEntity[json.RawMessage](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", pkg.Id), []PatchOperation{
+		{Op: "replace", Path: "/deleted", Value: false},
+	})
+	return err
+}
 
-	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", res.Status)
+// Activate or deactivate a package version, an inactive version is left out
+// of the repository index without being deleted
+func (c *Client) SetPackageActive(ctx context.Context, pkg model.Package, active bool) (model.Package, error) {
+	technology := strings.ToLower(pkg.Technology)
+	if technology != "python" && technology != "r" {
+		return model.Package{}, fmt.Errorf("invalid technology provided for activating only Python and R are supported")
+	}
+	path, err := technologyToPath(technology)
+	if err != nil {
+		return model.Package{}, err
 	}
 
-	return nil
+	return patchEntity[model.Package](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", pkg.Id), []PatchOperation{
+		{Op: "replace", Path: "/active", Value: active},
+	})
 }
 
-func DeletePackage(client *http.Client, cfg RDepotConfig, pkg model.Package) error {
+func (c *Client) DeletePackage(ctx context.Context, pkg model.Package) error {
 	if !pkg.Deleted {
-		err := SoftDeletePackage(client, cfg, pkg.Id)
+		err := c.SoftDeletePackage(ctx, pkg.Id)
 		if err != nil {
 			return err
 		}
 	}
 
-	if cfg.Technology != "python" && cfg.Technology != "r" {
+	if c.technology != "python" && c.technology != "r" {
 		return fmt.Errorf("invalid technology provided for deleting only Python and R are supported")
 	}
 	path, err := technologyToPath(strings.ToLower(pkg.Technology))
@@ -100,209 +98,318 @@ func DeletePackage(client *http.Client, cfg RDepotConfig, pkg model.Package) err
 		return err
 	}
 
-	req, err := http.NewRequest(
-		"DELETE",
-		cfg.Host+fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", pkg.Id),
-		nil)
+	req, err := c.newRequest(ctx, "DELETE", fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", pkg.Id), nil)
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Basic "+basicAuth(cfg.Username, cfg.Token))
-
-	res, err := client.Do(req)
+	res, err := c.do(req, http.StatusNoContent)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusNoContent {
-		return fmt.Errorf("bad status: %s", res.Status)
+	return nil
+}
+
+// Filters for listing packages. The server narrows the listing down where
+// its API supports a filter, the exact filters are then applied locally.
+type PackageQuery struct {
+	// Name of the repository
+	Repository string
+	// Glob pattern matched against the package name
+	Name string
+	// Only deleted packages when true, only packages that are not deleted
+	// when false and both when nil
+	Deleted *bool
+	// State of the submission of the package, e.g. 'accepted'
+	SubmissionState string
+	// Only packages that are not the newest version in their repository
+	Archived bool
+}
+
+// Query parameters for the filters the server can apply
+func (q PackageQuery) values() url.Values {
+	v := url.Values{}
+	if q.Repository != "" {
+		v.Set("repository", q.Repository)
+	}
+	if search := globLiteral(q.Name); search != "" {
+		v.Set("search", search)
+	}
+	if q.Deleted != nil {
+		v.Set("deleted", strconv.FormatBool(*q.Deleted))
+	}
+	if q.SubmissionState != "" {
+		v.Set("submissionState", strings.ToUpper(q.SubmissionState))
 	}
+	return v
+}
 
-	return nil
+// Exact check of the filters the server applies, packages without submission
+// state are kept since the state is unknown
+func (q PackageQuery) matches(pkg model.Package) bool {
+	if q.Repository != "" && pkg.Repository.Name != "" && pkg.Repository.Name != q.Repository {
+		return false
+	}
+	if q.Deleted != nil && pkg.Deleted != *q.Deleted {
+		return false
+	}
+	if q.SubmissionState != "" && pkg.Submission.State != "" && !strings.EqualFold(pkg.Submission.State, q.SubmissionState) {
+		return false
+	}
+	return true
 }
 
-func ListPackagesPage(client *http.Client, cfg RDepotConfig, repository string, page int) ([]byte, error) {
-	path, err := technologyToPath(cfg.Technology)
-	if err != nil {
-		return nil, err
+// Longest literal part of a glob pattern, the server can search for it to
+// narrow down the packages the pattern is matched against
+func globLiteral(pattern string) string {
+	var longest, current strings.Builder
+	flush := func() {
+		if current.Len() > longest.Len() {
+			longest.Reset()
+			longest.WriteString(current.String())
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?':
+			flush()
+		case '[':
+			flush()
+			// skip the character class, a ']' right after '[' or '[^' is literal
+			j := i + 1
+			if j < len(pattern) && pattern[j] == '^' {
+				j++
+			}
+			if j < len(pattern) && pattern[j] == ']' {
+				j++
+			}
+			for j < len(pattern) && pattern[j] != ']' {
+				if pattern[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			i = j
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				current.WriteByte(pattern[i])
+			}
+		default:
+			current.WriteByte(pattern[i])
+		}
 	}
+	flush()
+	return longest.String()
+}
 
-	req, err := http.NewRequest(
-		"GET",
-		cfg.Host+"/api/v2/manager/"+path+"packages",
-		nil)
+func (c *Client) ListPackagesPage(ctx context.Context, query PackageQuery, page int) ([]byte, error) {
+	path, err := technologyToPath(c.technology)
 	if err != nil {
 		return nil, err
 	}
 
-	q := req.URL.Query()
-	if repository != "" {
-		q.Add("repository", repository)
-	}
-	q.Add("page", strconv.Itoa(page))
-	q.Add("size", "100")
-	req.URL.RawQuery = q.Encode()
+	return c.getPage(ctx, "/api/v2/manager/"+path+"packages", query.values(), page)
+}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Basic "+basicAuth(cfg.Username, cfg.Token))
+func (c *Client) ListPackages(ctx context.Context, query PackageQuery) ([]model.Package, error) {
+	return ListGenericPackages[model.Package](ctx, c, query)
+}
 
-	res, err := client.Do(req)
+// List packages decoded as G, which is model.RPackage or model.PythonPackage
+// when the client technology is 'r' or 'python' and model.Package otherwise.
+// Go does not allow type parameters on methods, hence the function.
+func ListGenericPackages[G model.GenericPackage](ctx context.Context, c *Client, query PackageQuery) ([]G, error) {
+	return model.Collect(StreamGenericPackages[G](ctx, c, query))
+}
 
+// Stream packages decoded as G page by page, see ListGenericPackages. Only
+// the archived filter needs all packages before yielding the first one.
+func StreamGenericPackages[G model.GenericPackage](ctx context.Context, c *Client, query PackageQuery) model.Seq2[G, error] {
+	path, err := technologyToPath(c.technology)
 	if err != nil {
-		return nil, err
+		return func(yield func(G, error) bool) {
+			var zero G
+			yield(zero, err)
+		}
 	}
-	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad status: %s", res.Status)
-	}
+	pkgs := streamPages[G](ctx, c, "/api/v2/manager/"+path+"packages", query.values())
+	pkgs = model.FilterSeq(pkgs, func(pkg G) bool {
+		return query.matches(pkg.GetPackage())
+	})
 
-	body, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+	if query.Archived {
+		pkgs = model.FilterSeqArchived(pkgs)
 	}
-	return body, nil
-}
-
-func technologyToPath(s string) (string, error) {
-	switch s {
-	case "r":
-		return "r/", nil
-	case "python":
-		return "python/", nil
-	case "all":
-		return "", nil
-	default:
-		return "", fmt.Errorf("undefined technology %s", s)
+	if query.Name != "" {
+		pkgs = model.FilterSeqByName(pkgs, query.Name)
 	}
+	return pkgs
 }
 
-func ListPackages(client *http.Client, cfg RDepotConfig, repository string, archivedFilter bool, nameFilter string) ([]model.Package, error) {
-	return ListGenericPackages[model.Package](client, cfg, repository, archivedFilter, nameFilter)
+func (c *Client) StreamPackages(ctx context.Context, query PackageQuery) model.Seq2[model.Package, error] {
+	return StreamGenericPackages[model.Package](ctx, c, query)
 }
 
-func ListGenericPackages[G model.GenericPackage](client *http.Client, cfg RDepotConfig, repository string, archivedFilter bool, nameFilter string) ([]G, error) {
-	body, err := ListPackagesPage(client, cfg, repository, 0)
+func (c *Client) GetPackage(ctx context.Context, id int) (model.Package, error) {
+	return GetGenericPackage[model.Package](ctx, c, id)
+}
 
+// Fetch a single package decoded as G, see ListGenericPackages
+func GetGenericPackage[G model.GenericPackage](ctx context.Context, c *Client, id int) (G, error) {
+	path, err := technologyToPath(c.technology)
 	if err != nil {
-		return nil, err
+		var zero G
+		return zero, err
+	}
+
+	return getEntity[G](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"packages/%d", id))
+}
+
+// Look up a package that is not deleted by its exact name and fetch it. The
+// newest version is taken when version is empty. Without repository the
+// package has to be in a single repository.
+func FindGenericPackage[G model.GenericPackage](ctx context.Context, c *Client, name string, version string, repository string) (G, error) {
+	var found G
+
+	var wanted *model.Version
+	if version != "" {
+		var err error
+		if wanted, err = model.CanonicalVersion(version); err != nil {
+			return found, fmt.Errorf("invalid version %s: %w", version, err)
+		}
 	}
 
-	var response model.Response[G]
-	err = response.Unmarshal(body)
+	notDeleted := false
+	pkgs, err := ListGenericPackages[G](ctx, c, PackageQuery{
+		Name:       name,
+		Repository: repository,
+		Deleted:    &notDeleted,
+	})
 	if err != nil {
-		return nil, err
+		return found, err
 	}
 
-	var pkgs = response.Data.Content
-	for page := 1; page <= response.Data.Page.TotalPages; page++ {
-		new_body, err := ListPackagesPage(client, cfg, repository, page)
-		if err != nil {
-			return nil, err
+	var candidates []G
+	repositories := map[string]bool{}
+	for _, pkg := range pkgs {
+		if pkg.GetName() != name || (wanted != nil && !pkg.GetVersion().Equals(*wanted)) {
+			continue
 		}
-		err = response.Unmarshal(new_body)
-		if err != nil {
-			return nil, err
-		}
-		pkgs = append(pkgs, response.Data.Content...)
+		candidates = append(candidates, pkg)
+		repositories[pkg.GetPackage().Repository.Name] = true
 	}
 
-	if archivedFilter {
-		pkgs = model.FilterArchived(pkgs)
+	if len(candidates) == 0 {
+		return found, &NotFoundError{Kind: "package", Name: strings.TrimSpace(name + " " + version)}
 	}
-	if nameFilter != "" {
-		if pkgs, err = model.FilterByName(pkgs, nameFilter); err != nil {
-			return nil, err
+	if len(repositories) > 1 {
+		names := make([]string, 0, len(repositories))
+		for name := range repositories {
+			names = append(names, name)
 		}
+		sort.Strings(names)
+		return found, fmt.Errorf("package %s is in several repositories (%s), select one of them", name, strings.Join(names, ", "))
 	}
-	return pkgs, nil
+
+	found = candidates[0]
+	for _, pkg := range candidates[1:] {
+		if found.GetVersion().Less(pkg.GetVersion()) {
+			found = pkg
+		}
+	}
+	return GetGenericPackage[G](ctx, c, found.GetId())
 }
 
 type SubmissionResult struct {
-	Status      string `json:"status"`
-	Code        int    `json:"code"`
-	Message     string `json:"message"`
-	MessageCode string `json:"messageCode"`
+	Status      string           `json:"status"`
+	Code        int              `json:"code"`
+	Message     string           `json:"message"`
+	MessageCode string           `json:"messageCode"`
+	Data        model.Submission `json:"data"`
 }
 
-func SubmitPackage(client *http.Client, cfg RDepotConfig, archive string, repository string, replace bool, generateManual bool) (string, error) {
+func (c *Client) SubmitPackage(ctx context.Context, archive string, repository string, replace bool, generateManual bool) (SubmissionResult, error) {
 	var subres SubmissionResult
-	var msg string
 	var b bytes.Buffer
 
-	if cfg.Technology != "python" && cfg.Technology != "r" {
-		return msg, fmt.Errorf("invalid technology provided for deleting only Python and R are supported")
+	if c.technology != "python" && c.technology != "r" {
+		return subres, fmt.Errorf("invalid technology provided for deleting only Python and R are supported")
 	}
-	path, err := technologyToPath(cfg.Technology)
+	path, err := technologyToPath(c.technology)
 	if err != nil {
-		return msg, err
+		return subres, err
 	}
 
 	w := multipart.NewWriter(&b)
 
 	fr, err := os.Open(archive)
 	if err != nil {
-		return msg, err
+		return subres, err
 	}
+	defer fr.Close()
 
-	if fw, err := createFormGZip(w, "file", archive); err != nil {
-		return msg, err
+	contentType, err := archiveContentType(fr, c.technology, filepath.Base(archive))
+	if err != nil {
+		return subres, err
+	}
+
+	if fw, err := createFormFile(w, "file", filepath.Base(archive), contentType); err != nil {
+		return subres, err
 	} else {
 		io.Copy(fw, fr)
 	}
 
 	if err := w.WriteField("repository", repository); err != nil {
-		return msg, err
+		return subres, err
 	}
 
 	if err := w.WriteField("replace", strconv.FormatBool(replace)); err != nil {
-		return msg, err
+		return subres, err
 	}
 
 	if !generateManual { // TODO: remove in future versions
 		if err := w.WriteField("generateManual", strconv.FormatBool(generateManual)); err != nil {
-			return msg, err
+			return subres, err
 		}
 	}
 
 	w.Close()
 
-	req, err := http.NewRequest(
-		"POST",
-		cfg.Host+"/api/v2/manager/"+path+"submissions",
-		&b)
+	req, err := c.newRequest(ctx, "POST", "/api/v2/manager/"+path+"submissions", &b)
 	if err != nil {
-		return msg, err
+		return subres, err
 	}
-
 	req.Header.Set("Content-Type", w.FormDataContentType())
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Basic "+basicAuth(cfg.Username, cfg.Token))
 
-	res, err := client.Do(req)
+	res, err := c.do(req, http.StatusCreated, http.StatusOK)
 	if err != nil {
-		return msg, err
+		return subres, err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
-		return msg, fmt.Errorf("bad status: %s", res.Status)
-	}
-
-	defer res.Body.Close()
 	resBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		return msg, err
+		return subres, err
 	}
 	if err := json.Unmarshal(resBody, &subres); err != nil {
-		return msg, fmt.Errorf("could not unpack response: %s", err)
+		return subres, fmt.Errorf("could not unpack response: %s", err)
 	}
-	msg = subres.Message
-	return msg, nil
+	return subres, nil
+}
 
+// Warnings of the server about an accepted submission, given as the message
+// of a response with status WARNING
+func (r SubmissionResult) Warnings() []string {
+	if strings.EqualFold(r.Status, "WARNING") && r.Message != "" {
+		return []string{r.Message}
+	}
+	return nil
 }
 
 var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -311,13 +418,57 @@ func escapeQuotes(s string) string {
 	return quoteEscaper.Replace(s)
 }
 
-func createFormGZip(w *multipart.Writer, fieldname, path string) (io.Writer, error) {
+func createFormFile(w *multipart.Writer, fieldname, filename, contentType string) (io.Writer, error) {
 	h := make(textproto.MIMEHeader)
-	splitPath := strings.Split(path, "/")
-	filename := splitPath[len(splitPath)-1]
 	h.Set("Content-Disposition",
 		fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
 			escapeQuotes(fieldname), escapeQuotes(filename)))
-	h.Set("Content-Type", "application/gzip")
+	h.Set("Content-Type", contentType)
 	return w.CreatePart(h)
 }
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04}
+)
+
+// Detect the content type of an archive from its magic bytes and check that
+// it matches the archive name expected for the technology. The reader is
+// rewound to the start afterwards.
+func archiveContentType(r io.ReadSeeker, technology string, filename string) (string, error) {
+	magic := make([]byte, 4)
+	n, err := io.ReadFull(r, magic)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	var contentType string
+	switch {
+	case bytes.HasPrefix(magic[:n], gzipMagic):
+		contentType = "application/gzip"
+	case bytes.HasPrefix(magic[:n], zipMagic):
+		contentType = "application/zip"
+	default:
+		return "", fmt.Errorf("%s is neither a gzip nor a zip archive", filename)
+	}
+
+	expected := "application/gzip"
+	if technology == "python" {
+		if _, err := model.ParsePythonFilename(filename); err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(filename, ".whl") || strings.HasSuffix(filename, ".zip") {
+			expected = "application/zip"
+		}
+	} else if !strings.HasSuffix(filename, ".tar.gz") {
+		return "", fmt.Errorf("invalid R package archive %s, expected a .tar.gz file", filename)
+	}
+
+	if contentType != expected {
+		return "", fmt.Errorf("%s is a %s archive, expected %s", filename, contentType, expected)
+	}
+	return contentType, nil
+}
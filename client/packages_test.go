@@ -15,11 +15,20 @@
 package client
 
 import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
+
+	"openanalytics.eu/rdepot/cli/model"
 )
 
 func TestListPackages(t *testing.T) {
@@ -51,9 +60,9 @@ func TestListPackages(t *testing.T) {
 		}))
 		defer server.Close()
 
-		config := RDepotConfig{Host: server.URL, Token: "validtoken", Technology: "all"}
+		c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("all"), WithHTTPClient(server.Client()))
 
-		res, err := ListPackages(server.Client(), config, "", false, "")
+		res, err := c.ListPackages(context.Background(), PackageQuery{})
 
 		if err != nil {
 			t.Errorf("Got error: %s", err)
@@ -75,13 +84,19 @@ func expectEqual(t *testing.T, expected interface{}, actual interface{}) {
 
 func TestSubmitPackage(t *testing.T) {
 	var tests = []struct {
-		body    []byte
-		replace bool
+		body     []byte
+		replace  bool
+		warnings int
 	}{
 		{
-			body:    []byte(`{"status": "SUCCESS", "code": 201, "message": "Your resource has been created successfully.", "messageCode": "success.resource.created", "data": {}}`),
+			body:    []byte(`{"status": "SUCCESS", "code": 201, "message": "Your resource has been created successfully.", "messageCode": "success.resource.created", "data": {"id": 12, "state": "WAITING"}}`),
 			replace: false,
 		},
+		{
+			body:     []byte(`{"status": "WARNING", "code": 201, "message": "Package has no manual.", "messageCode": "warning.manual.missing", "data": {"id": 12, "state": "WAITING"}}`),
+			replace:  true,
+			warnings: 1,
+		},
 	}
 
 	for _, test := range tests {
@@ -103,12 +118,231 @@ func TestSubmitPackage(t *testing.T) {
 		}))
 		defer server.Close()
 
-		config := RDepotConfig{Host: server.URL, Token: "validtoken", Technology: "r"}
+		c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
 
-		_, err := SubmitPackage(server.Client(), config, "testdata/oaColors_0.0.4.tar.gz", "test", test.replace, true)
+		res, err := c.SubmitPackage(context.Background(), "testdata/oaColors_0.0.4.tar.gz", "test", test.replace, true)
 
 		if err != nil {
 			t.Errorf("Error: %s", err)
 		}
+		expectEqual(t, 12, res.Data.Id)
+		expectEqual(t, test.warnings, len(res.Warnings()))
+	}
+}
+
+func writeZip(t *testing.T, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	if _, err := w.Create("example_pkg/__init__.py"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSubmitPythonPackage(t *testing.T) {
+	dir := t.TempDir()
+	wheel := filepath.Join(dir, "example_pkg-0.1.0-py3-none-any.whl")
+	writeZip(t, wheel)
+	badName := filepath.Join(dir, "example_pkg.whl")
+	writeZip(t, badName)
+	fakeSdist := filepath.Join(dir, "example_pkg-0.1.0.tar.gz")
+	writeZip(t, fakeSdist)
+
+	var tests = []struct {
+		archive     string
+		contentType string
+		valid       bool
+	}{
+		{archive: wheel, contentType: "application/zip", valid: true},
+		{archive: "testdata/oaColors_0.0.4.tar.gz", valid: false},
+		{archive: badName, valid: false},
+		{archive: fakeSdist, valid: false},
+	}
+
+	for _, test := range tests {
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			expectEqual(t, "/api/v2/manager/python/submissions", req.URL.String())
+			if _, fh, err := req.FormFile("file"); err != nil {
+				t.Errorf("Error: %s", err)
+			} else {
+				expectEqual(t, test.contentType, fh.Header.Get("Content-Type"))
+				expectEqual(t, filepath.Base(test.archive), fh.Filename)
+			}
+			rw.WriteHeader(http.StatusCreated)
+			rw.Write([]byte(`{"status": "SUCCESS", "code": 201, "data": {"id": 13}}`))
+		}))
+		defer server.Close()
+
+		c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("python"), WithHTTPClient(server.Client()))
+
+		_, err := c.SubmitPackage(context.Background(), test.archive, "pyrepo", true, true)
+
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", test.archive, err)
+		} else if !test.valid && err == nil {
+			t.Errorf("%s: expected error", test.archive)
+		}
+	}
+}
+
+func TestGlobLiteral(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		literal string
+	}{
+		{pattern: "", literal: ""},
+		{pattern: "accrued", literal: "accrued"},
+		{pattern: "acc*", literal: "acc"},
+		{pattern: "*ued?x", literal: "ued"},
+		{pattern: "oa[A-Z]olors", literal: "olors"},
+		{pattern: "a[]b]cd", literal: "cd"},
+		{pattern: "foo\\*bar*", literal: "foo*bar"},
+		{pattern: "*", literal: ""},
+	}
+
+	for _, test := range tests {
+		expectEqual(t, test.literal, globLiteral(test.pattern))
+	}
+}
+
+func TestListPackagesQuery(t *testing.T) {
+	body := []byte(`{ "status": "SUCCESS", "code": 200, "data": { "content": [
+		{ "id": 1, "name": "accrued", "version": "1.2", "deleted": true, "repository": { "name": "testrepo1" }, "submission": { "state": "ACCEPTED" } },
+		{ "id": 2, "name": "accrued", "version": "1.3", "deleted": false, "repository": { "name": "testrepo1" }, "submission": { "state": "ACCEPTED" } },
+		{ "id": 3, "name": "accruedx", "version": "1.0", "deleted": true, "repository": { "name": "testrepo1" }, "submission": { "state": "ACCEPTED" } },
+		{ "id": 4, "name": "accrued", "version": "1.1", "deleted": true, "repository": { "name": "testrepo1" }, "submission": { "state": "WAITING" } }
+	], "page": { "size": 4, "totalElements": 4, "totalPages": 1, "number": 0 } }}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		expectEqual(t, "testrepo1", q.Get("repository"))
+		expectEqual(t, "accrued", q.Get("search"))
+		expectEqual(t, "true", q.Get("deleted"))
+		expectEqual(t, "ACCEPTED", q.Get("submissionState"))
+		rw.Write(body)
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+	deleted := true
+	pkgs, err := c.ListPackages(context.Background(), PackageQuery{
+		Repository:      "testrepo1",
+		Name:            "accrued",
+		Deleted:         &deleted,
+		SubmissionState: "accepted",
+	})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("Expected 1 package, got %d", len(pkgs))
+	}
+	expectEqual(t, 1, pkgs[0].Id)
+}
+
+func TestFindPackage(t *testing.T) {
+	list := []byte(`{ "status": "SUCCESS", "code": 200, "data": { "content": [
+		{ "id": 1, "name": "accrued", "version": "1.2", "repository": { "name": "testrepo1" } },
+		{ "id": 2, "name": "accrued", "version": "1.10", "repository": { "name": "testrepo1" } },
+		{ "id": 3, "name": "accruedx", "version": "2.0", "repository": { "name": "testrepo1" } },
+		{ "id": 4, "name": "accrued", "version": "1.1", "repository": { "name": "testrepo2" } }
+	], "page": { "size": 4, "totalElements": 4, "totalPages": 1, "number": 0 } }}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/api/v2/manager/r/packages" {
+			expectEqual(t, "false", req.URL.Query().Get("deleted"))
+			rw.Write(list)
+			return
+		}
+		var id int
+		if _, err := fmt.Sscanf(req.URL.Path, "/api/v2/manager/r/packages/%d", &id); err != nil {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(rw, `{ "status": "SUCCESS", "code": 200, "data": { "id": %d, "name": "accrued", "version": "1.0", "md5sum": "abc" } }`, id)
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithTechnology("r"), WithHTTPClient(server.Client()))
+	ctx := context.Background()
+
+	var tests = []struct {
+		version    string
+		repository string
+		id         int
+	}{
+		{version: "", repository: "testrepo1", id: 2},
+		{version: "1.2", repository: "testrepo1", id: 1},
+		{version: "1.1", repository: "", id: 4},
+	}
+	for _, test := range tests {
+		pkg, err := FindGenericPackage[model.RPackage](ctx, c, "accrued", test.version, test.repository)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		expectEqual(t, test.id, pkg.Id)
+		expectEqual(t, "abc", pkg.Md5sum)
+	}
+
+	if _, err := FindGenericPackage[model.RPackage](ctx, c, "accrued", "", ""); err == nil {
+		t.Errorf("Expected error for a package in several repositories")
+	}
+	var notFound *NotFoundError
+	if _, err := FindGenericPackage[model.RPackage](ctx, c, "accrued", "3.0", ""); !errors.As(err, &notFound) {
+		t.Errorf("Expected NotFoundError, got %v", err)
+	}
+}
+
+func TestSetPackageActive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "PATCH", req.Method)
+		expectEqual(t, "/api/v2/manager/python/packages/5", req.URL.Path)
+		body, _ := io.ReadAll(req.Body)
+		expectEqual(t, `[{"op":"replace","path":"/active","value":false}]`, string(body))
+		rw.Write([]byte(`{ "status": "SUCCESS", "code": 200, "data": { "id": 5, "name": "py-pkg", "version": "1.0", "active": false } }`))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	pkg, err := c.SetPackageActive(context.Background(), model.Package{Id: 5, Name: "py-pkg", Technology: "Python", Active: true}, false)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, false, pkg.Active)
+
+	for _, technology := range []string{"", "all", "Julia"} {
+		if _, err := c.SetPackageActive(context.Background(), model.Package{Id: 5, Technology: technology}, false); err == nil {
+			t.Errorf("expected error for technology %q", technology)
+		}
+	}
+}
+
+func TestRestorePackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "PATCH", req.Method)
+		expectEqual(t, "/api/v2/manager/r/packages/3", req.URL.Path)
+		body, _ := io.ReadAll(req.Body)
+		expectEqual(t, `[{"op":"replace","path":"/deleted","value":false}]`, string(body))
+		rw.Write([]byte(`{ "status": "SUCCESS", "code": 200, "data": { "id": 3, "name": "accrued", "version": "1.2", "deleted": false } }`))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+	if err := c.RestorePackage(context.Background(), model.Package{Id: 3, Name: "accrued", Technology: "R", Deleted: true}); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+
+	for _, technology := range []string{"", "all", "Julia"} {
+		if err := c.RestorePackage(context.Background(), model.Package{Id: 3, Technology: technology, Deleted: true}); err == nil {
+			t.Errorf("expected error for technology %q", technology)
+		}
 	}
 }
@@ -0,0 +1,134 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+const (
+	DefaultPageSize        = 100
+	DefaultPageConcurrency = 4
+)
+
+// Fetch a single page of a paged v2 API resource
+func (c *Client) getPage(ctx context.Context, path string, query url.Values, page int) ([]byte, error) {
+	req, err := c.newRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("page", strconv.Itoa(page))
+	q.Set("size", strconv.Itoa(c.pageSize))
+	req.URL.RawQuery = q.Encode()
+
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	return io.ReadAll(res.Body)
+}
+
+// Fetch and decode a single page of a paged v2 API resource
+func fetchPage[C any](ctx context.Context, c *Client, path string, query url.Values, page int) (model.Data[C], error) {
+	var response model.Response[C]
+
+	body, err := c.getPage(ctx, path, query, page)
+	if err != nil {
+		return response.Data, err
+	}
+	err = response.Unmarshal(body)
+	return response.Data, err
+}
+
+type pageResult[C any] struct {
+	content []C
+	err     error
+}
+
+// Stream the content of all pages of a paged v2 API resource. The first page
+// tells how many pages there are, up to pageConcurrency of the next pages are
+// fetched ahead while the items are yielded in order.
+func streamPages[C any](ctx context.Context, c *Client, path string, query url.Values) model.Seq2[C, error] {
+	return func(yield func(C, error) bool) {
+		var zero C
+
+		ctx, cancel := context.WithCancel(ctx)
+		// stops the pages still in flight when the consumer stops early
+		defer cancel()
+
+		first, err := fetchPage[C](ctx, c, path, query, 0)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		fetch := func(page int) chan pageResult[C] {
+			result := make(chan pageResult[C], 1)
+			go func() {
+				data, err := fetchPage[C](ctx, c, path, query, page)
+				result <- pageResult[C]{data.Content, err}
+			}()
+			return result
+		}
+
+		var pending []chan pageResult[C]
+		next := 1
+		for ; next < first.Page.TotalPages && len(pending) < c.pageConcurrency; next++ {
+			pending = append(pending, fetch(next))
+		}
+
+		content := first.Content
+		for {
+			for _, item := range content {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(pending) == 0 {
+				return
+			}
+
+			result := <-pending[0]
+			pending = pending[1:]
+			if result.err != nil {
+				yield(zero, result.err)
+				return
+			}
+			if next < first.Page.TotalPages {
+				pending = append(pending, fetch(next))
+				next++
+			}
+			content = result.content
+		}
+	}
+}
+
+// Fetch and concatenate the content of all pages of a paged v2 API resource
+func listPages[C any](ctx context.Context, c *Client, path string, query url.Values) ([]C, error) {
+	return model.Collect(streamPages[C](ctx, c, path, query))
+}
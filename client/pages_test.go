@@ -0,0 +1,136 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+// A paged packages endpoint serving total packages, taking latency per page
+func pagedServer(t testing.TB, total int, latency time.Duration, failPage int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(req.URL.Query().Get("size"))
+		if page == failPage {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// later pages answer faster to shuffle the completion order
+		time.Sleep(latency / time.Duration(page+1))
+
+		content := []map[string]interface{}{}
+		for id := page * size; id < (page+1)*size && id < total; id++ {
+			content = append(content, map[string]interface{}{"id": id, "name": fmt.Sprintf("pkg%d", id), "version": "1.0"})
+		}
+		response := map[string]interface{}{
+			"status": "SUCCESS",
+			"code":   http.StatusOK,
+			"data": map[string]interface{}{
+				"content": content,
+				"page":    model.Page{Size: size, TotalElements: total, TotalPages: (total + size - 1) / size, Number: page},
+			},
+		}
+		if err := json.NewEncoder(rw).Encode(response); err != nil {
+			t.Errorf("Error: %s", err)
+		}
+	}))
+}
+
+func TestListPagesConcurrently(t *testing.T) {
+	server := pagedServer(t, 95, 10*time.Millisecond, -1)
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithTechnology("all"), WithHTTPClient(server.Client()), WithPageSize(10), WithPageConcurrency(4))
+
+	pkgs, err := c.ListPackages(context.Background(), PackageQuery{})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, 95, len(pkgs))
+	for i, pkg := range pkgs {
+		if pkg.Id != i {
+			t.Fatalf("Expected package %d at position %d, got %d", i, i, pkg.Id)
+		}
+	}
+}
+
+func TestListPagesFailure(t *testing.T) {
+	server := pagedServer(t, 95, time.Millisecond, 3)
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithTechnology("all"), WithHTTPClient(server.Client()), WithPageSize(10))
+
+	_, err := c.ListPackages(context.Background(), PackageQuery{})
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("Expected an APIError, got %v", err)
+	}
+	expectEqual(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
+
+func BenchmarkListPages(b *testing.B) {
+	server := pagedServer(b, 2000, 5*time.Millisecond, -1)
+	defer server.Close()
+
+	for _, concurrency := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			c := New(WithBaseURL(server.URL), WithTechnology("all"), WithHTTPClient(server.Client()), WithPageSize(50), WithPageConcurrency(concurrency))
+			for i := 0; i < b.N; i++ {
+				if _, err := c.ListPackages(context.Background(), PackageQuery{}); err != nil {
+					b.Fatalf("Error: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamPackagesStopsEarly(t *testing.T) {
+	var requests int32
+
+	server := pagedServer(t, 95, time.Millisecond, -1)
+	defer server.Close()
+	counting := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		counting.ServeHTTP(rw, req)
+	})
+
+	c := New(WithBaseURL(server.URL), WithTechnology("all"), WithHTTPClient(server.Client()), WithPageSize(10), WithPageConcurrency(2))
+
+	var ids []int
+	c.StreamPackages(context.Background(), PackageQuery{})(func(pkg model.Package, err error) bool {
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		ids = append(ids, pkg.Id)
+		return len(ids) < 15
+	})
+
+	expectEqual(t, 15, len(ids))
+	expectEqual(t, 14, ids[14])
+	if n := atomic.LoadInt32(&requests); n > 4 {
+		t.Errorf("Expected at most 4 page requests, got %d", n)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+type repositoryCreate struct {
+	Name           string `json:"name"`
+	PublicationUri string `json:"publicationUri"`
+	ServerAddress  string `json:"serverAddress"`
+	HashMethod     string `json:"hashMethod,omitempty"`
+}
+
+func (c *Client) ListRepositories(ctx context.Context, nameFilter string) ([]model.Repository, error) {
+	path, err := technologyToPath(c.technology)
+	if err != nil {
+		return nil, err
+	}
+
+	repos, err := listPages[model.Repository](ctx, c, "/api/v2/manager/"+path+"repositories", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	if nameFilter != "" {
+		if repos, err = model.FilterByName(repos, nameFilter); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+// Look up a single repository by its exact name
+func (c *Client) GetRepository(ctx context.Context, name string) (model.Repository, error) {
+	repos, err := c.ListRepositories(ctx, "")
+	if err != nil {
+		return model.Repository{}, err
+	}
+	for _, repo := range repos {
+		if repo.Name == name {
+			return repo, nil
+		}
+	}
+	return model.Repository{}, &NotFoundError{Kind: "repository", Name: name}
+}
+
+func (c *Client) CreateRepository(ctx context.Context, repo model.Repository) (model.Repository, error) {
+	var created model.Repository
+
+	if c.technology != "python" && c.technology != "r" {
+		return created, fmt.Errorf("invalid technology provided for creating only Python and R are supported")
+	}
+	path, err := technologyToPath(c.technology)
+	if err != nil {
+		return created, err
+	}
+
+	body, err := json.Marshal(repositoryCreate{
+		Name:           repo.Name,
+		PublicationUri: repo.PublicationUri,
+		ServerAddress:  repo.ServerAddress,
+		HashMethod:     repo.HashMethod,
+	})
+	if err != nil {
+		return created, err
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/api/v2/manager/"+path+"repositories", bytes.NewBuffer(body))
+	if err != nil {
+		return created, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.do(req, http.StatusCreated, http.StatusOK)
+	if err != nil {
+		return created, err
+	}
+	defer res.Body.Close()
+
+	return decodeEntity[model.Repository](res.Body)
+}
+
+func (c *Client) PatchRepository(ctx context.Context, repo model.Repository, patch []PatchOperation) (model.Repository, error) {
+	path, err := technologyToPath(strings.ToLower(repo.Technology))
+	if err != nil {
+		return model.Repository{}, err
+	}
+	if path == "" {
+		return model.Repository{}, fmt.Errorf("invalid technology provided for patching only Python and R are supported")
+	}
+
+	return patchEntity[model.Repository](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"repositories/%d", repo.Id), patch)
+}
+
+func (c *Client) DeleteRepository(ctx context.Context, repo model.Repository) error {
+	if !repo.Deleted {
+		_, err := c.PatchRepository(ctx, repo, []PatchOperation{
+			{Op: "replace", Path: "/deleted", Value: true},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	path, err := technologyToPath(strings.ToLower(repo.Technology))
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("invalid technology provided for deleting only Python and R are supported")
+	}
+
+	req, err := c.newRequest(ctx, "DELETE", fmt.Sprintf("/api/v2/manager/"+path+"repositories/%d", repo.Id), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.do(req, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return nil
+}
+
+func (c *Client) PublishRepository(ctx context.Context, repo model.Repository, published bool) (model.Repository, error) {
+	return c.PatchRepository(ctx, repo, []PatchOperation{
+		{Op: "replace", Path: "/published", Value: published},
+	})
+}
+
+// State of the package index served at a repository's publication URI
+type IndexSnapshot struct {
+	StatusCode int
+	Digest     string
+}
+
+func (s IndexSnapshot) Served() bool {
+	return s.StatusCode == http.StatusOK
+}
+
+func repositoryIndexUrl(repo model.Repository) (string, error) {
+	uri := strings.TrimSuffix(repo.PublicationUri, "/")
+	if uri == "" {
+		return "", fmt.Errorf("repository %s has no publication URI", repo.Name)
+	}
+	switch strings.ToLower(repo.Technology) {
+	case "r":
+		return uri + "/src/contrib/PACKAGES", nil
+	case "python":
+		return uri + "/simple/", nil
+	default:
+		return "", fmt.Errorf("undefined technology %s", repo.Technology)
+	}
+}
+
+// Fetch the package index of a repository from its publication URI. The
+// credentials of the client are only sent along when the index requires
+// authentication and is served from the same origin as the API.
+func (c *Client) GetRepositoryIndex(ctx context.Context, repo model.Repository) (IndexSnapshot, error) {
+	var snapshot IndexSnapshot
+
+	indexUrl, err := repositoryIndexUrl(repo)
+	if err != nil {
+		return snapshot, &IndexError{Repository: repo.Name, Reason: err.Error()}
+	}
+	u, err := url.Parse(indexUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return snapshot, &IndexError{Repository: repo.Name, Reason: fmt.Sprintf("invalid publication URI %q", repo.PublicationUri)}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return snapshot, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	authenticated := false
+	if repo.RequiresAuthentication && c.auth != nil && c.sameOrigin(u) {
+		if err := c.auth.Authenticate(req); err != nil {
+			return snapshot, err
+		}
+		authenticated = true
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return snapshot, err
+	}
+	defer res.Body.Close()
+
+	snapshot.StatusCode = res.StatusCode
+	switch res.StatusCode {
+	case http.StatusOK:
+		hash := sha256.New()
+		if _, err := io.Copy(hash, res.Body); err != nil {
+			return snapshot, err
+		}
+		snapshot.Digest = hex.EncodeToString(hash.Sum(nil))
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// not served to this client, which may change once it is published
+		err := fmt.Errorf("index of repository %s at %s: %s", repo.Name, u.Redacted(), res.Status)
+		if repo.RequiresAuthentication && c.auth != nil && !authenticated {
+			err = fmt.Errorf("%w, credentials are only sent to the RDepot server", err)
+		}
+		return snapshot, err
+	}
+	return snapshot, nil
+}
+
+// Whether a URL has the scheme and host of the API
+func (c *Client) sameOrigin(u *url.URL) bool {
+	base, err := url.Parse(c.baseURL)
+	return err == nil && strings.EqualFold(base.Scheme, u.Scheme) && strings.EqualFold(base.Host, u.Host)
+}
+
+// Fetch the current state of a repository by its id
+func (c *Client) refreshRepository(ctx context.Context, repo model.Repository) (model.Repository, error) {
+	path, err := technologyToPath(strings.ToLower(repo.Technology))
+	if err != nil {
+		return repo, err
+	}
+	if path == "" {
+		return repo, fmt.Errorf("repository %s has no technology", repo.Name)
+	}
+	return getEntity[model.Repository](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"repositories/%d", repo.Id))
+}
+
+// Wait until a change of the published state of a repository took effect,
+// polling until ctx is done. Publishing is complete when the publication URI
+// serves a package index that differs from before, the one captured before
+// the change, and fails early when the server reports an unsuccessful
+// publication after the one of repo, the state before the change.
+// Unpublishing is complete when the publication URI stops serving an index.
+// Errors that polling cannot resolve, such as a request rejected by the API
+// or an invalid publication URI, are returned right away.
+func (c *Client) WaitForPublication(ctx context.Context, repo model.Repository, before IndexSnapshot, published bool, interval time.Duration) error {
+	var lastErr error
+	for {
+		if published {
+			current, err := c.refreshRepository(ctx, repo)
+			switch {
+			case err != nil && permanent(err):
+				return fmt.Errorf("could not check the publication of repository %s: %w", repo.Name, err)
+			case err != nil:
+				lastErr = err
+			case current.LastPublicationTimestamp != "" && current.LastPublicationTimestamp != repo.LastPublicationTimestamp &&
+				!current.LastPublicationSuccessful:
+				return fmt.Errorf("publication of repository %s failed", repo.Name)
+			}
+		}
+
+		snapshot, err := c.GetRepositoryIndex(ctx, repo)
+		switch {
+		case !published && snapshot.StatusCode != 0 && !snapshot.Served():
+			return nil
+		case err != nil && permanent(err):
+			return err
+		case err != nil:
+			lastErr = err
+		case published && snapshot.Served() && (!before.Served() || snapshot.Digest != before.Digest):
+			return nil
+		}
+
+		if err := sleep(ctx, interval); err != nil {
+			if lastErr != nil {
+				return fmt.Errorf("stopped waiting for repository %s: %w, last error: %v", repo.Name, err, lastErr)
+			}
+			return fmt.Errorf("stopped waiting for repository %s: %w", repo.Name, err)
+		}
+	}
+}
+
+// Whether an error stays the same however long one waits: a request the API
+// rejects or a repository without a valid publication URI
+func permanent(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+			apiErr.StatusCode != http.StatusRequestTimeout && apiErr.StatusCode != http.StatusTooManyRequests
+	}
+	var indexErr *IndexError
+	return errors.As(err, &indexErr)
+}
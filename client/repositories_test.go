@@ -0,0 +1,330 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+var repositoriesBody = []byte(`{ "status": "SUCCESS", "code": 200, "message": "Your request has been processed successfully.", "messageCode": "success.request.processed", "data": { "links": [], "content": [ { "id": 2, "version": 8, "publicationUri": "http://localhost/repo/testrepo1", "name": "testrepo1", "serverAddress": "http://oa-rdepot-repo:8080/testrepo1", "deleted": false, "published": true, "synchronizing": false, "technology": "R" }, { "id": 3, "version": 3, "publicationUri": "http://localhost/repo/testrepo2", "name": "testrepo2", "serverAddress": "http://oa-rdepot-repo:8080/testrepo2", "deleted": false, "published": false, "synchronizing": false, "technology": "R" } ], "page": { "size": 2, "totalElements": 2, "totalPages": 1, "number": 0 } }}`)
+
+func TestListRepositories(t *testing.T) {
+
+	var tests = []struct {
+		technology string
+		path       string
+		name       string
+		nRepos     int
+	}{
+		{technology: "r", path: "/api/v2/manager/r/repositories", name: "", nRepos: 2},
+		{technology: "all", path: "/api/v2/manager/repositories", name: "", nRepos: 2},
+		{technology: "r", path: "/api/v2/manager/r/repositories", name: "*2", nRepos: 1},
+	}
+
+	for _, test := range tests {
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			expectEqual(t, test.path, req.URL.Path)
+			expectEqual(t, "0", req.URL.Query().Get("page"))
+			rw.Write(repositoriesBody)
+		}))
+		defer server.Close()
+
+		c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology(test.technology), WithHTTPClient(server.Client()))
+
+		res, err := c.ListRepositories(context.Background(), test.name)
+
+		if err != nil {
+			t.Errorf("Got error: %s", err)
+		}
+
+		if len(res) != test.nRepos {
+			t.Errorf("Expected %d repositories, got %d", test.nRepos, len(res))
+		}
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write(repositoriesBody)
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+	repo, err := c.GetRepository(context.Background(), "testrepo2")
+	if err != nil {
+		t.Fatalf("Got error: %s", err)
+	}
+	expectEqual(t, 3, repo.Id)
+	expectEqual(t, "http://oa-rdepot-repo:8080/testrepo2", repo.ServerAddress)
+
+	if _, err := c.GetRepository(context.Background(), "testrepo3"); err == nil {
+		t.Errorf("Expected error for unknown repository")
+	}
+}
+
+func TestCreateRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "POST", req.Method)
+		expectEqual(t, "/api/v2/manager/python/repositories", req.URL.Path)
+
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("Error: %s", err)
+		}
+		expectEqual(t, "pyrepo", body["name"])
+		expectEqual(t, "http://localhost/repo/pyrepo", body["publicationUri"])
+		expectEqual(t, "http://oa-rdepot-repo:8080/pyrepo", body["serverAddress"])
+
+		rw.WriteHeader(http.StatusCreated)
+		rw.Write([]byte(`{"status": "SUCCESS", "code": 201, "message": "Your resource has been created successfully.", "messageCode": "success.resource.created", "data": {"id": 7, "name": "pyrepo", "technology": "Python"}}`))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("python"), WithHTTPClient(server.Client()))
+
+	repo, err := c.CreateRepository(context.Background(), model.Repository{
+		Name:           "pyrepo",
+		PublicationUri: "http://localhost/repo/pyrepo",
+		ServerAddress:  "http://oa-rdepot-repo:8080/pyrepo",
+	})
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, 7, repo.Id)
+
+	c = New(WithBaseURL(server.URL), WithTechnology("all"), WithHTTPClient(server.Client()))
+	if _, err := c.CreateRepository(context.Background(), model.Repository{Name: "pyrepo"}); err == nil {
+		t.Errorf("Expected error for technology all")
+	}
+}
+
+func TestDeleteRepository(t *testing.T) {
+	var methods []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		methods = append(methods, req.Method)
+		expectEqual(t, "/api/v2/manager/r/repositories/3", req.URL.Path)
+		switch req.Method {
+		case "PATCH":
+			body, _ := io.ReadAll(req.Body)
+			expectEqual(t, `[{"op":"replace","path":"/deleted","value":true}]`, string(body))
+			expectEqual(t, "application/json-patch+json", req.Header.Get("Content-Type"))
+			rw.Write([]byte(`{"status": "SUCCESS", "code": 200, "data": {"id": 3, "deleted": true}}`))
+		case "DELETE":
+			rw.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+	if err := c.DeleteRepository(context.Background(), model.Repository{Id: 3, Technology: "R"}); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if len(methods) != 2 || methods[0] != "PATCH" || methods[1] != "DELETE" {
+		t.Errorf("Expected PATCH followed by DELETE, got %v", methods)
+	}
+}
+
+func TestWaitForPublication(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v2/manager/r/repositories/2":
+			rw.Write([]byte(`{ "status": "SUCCESS", "code": 200, "data": { "id": 2, "name": "testrepo1" } }`))
+		case "/repo/testrepo1/src/contrib/PACKAGES":
+			requests++
+			if requests < 3 {
+				rw.WriteHeader(http.StatusNotFound)
+				return
+			}
+			rw.Write([]byte("Package: accrued\nVersion: 1.2\n"))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := model.Repository{Id: 2, Name: "testrepo1", Technology: "R", PublicationUri: server.URL + "/repo/testrepo1"}
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	before, err := c.GetRepositoryIndex(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if before.Served() {
+		t.Errorf("Expected index not to be served yet")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := c.WaitForPublication(ctx, repo, before, true, time.Millisecond); err != nil {
+		t.Errorf("Error: %s", err)
+	}
+	expectEqual(t, 3, requests)
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	err = c.WaitForPublication(short, repo, before, false, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while index is still served, got %v", err)
+	}
+}
+
+func TestWaitForPublicationTimestamp(t *testing.T) {
+	var successful, changed bool
+	var authorization string
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v2/manager/r/repositories/2":
+			fmt.Fprintf(rw, `{ "status": "SUCCESS", "code": 200, "data": { "id": 2, "name": "testrepo1", "published": true,
+				"lastPublicationTimestamp": "2024-05-02T10:00:00", "lastPublicationSuccessful": %t } }`, successful)
+		case "/repo/testrepo1/src/contrib/PACKAGES":
+			authorization = req.Header.Get("Authorization")
+			rw.Write([]byte("Package: accrued\nVersion: 1.2\n"))
+			if changed {
+				rw.Write([]byte("\nPackage: oaColors\nVersion: 0.0.4\n"))
+			}
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := model.Repository{
+		Id:                       2,
+		Name:                     "testrepo1",
+		Technology:               "R",
+		PublicationUri:           server.URL + "/repo/testrepo1",
+		RequiresAuthentication:   true,
+		LastPublicationTimestamp: "2024-05-01T10:00:00",
+	}
+	c := New(WithBaseURL(server.URL), WithBasicAuth("user", "token"), WithHTTPClient(server.Client()))
+
+	before, err := c.GetRepositoryIndex(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if !before.Served() || authorization == "" {
+		t.Errorf("Expected the index to be fetched with credentials")
+	}
+
+	// a successful publication only completes once the new index is served
+	successful = true
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if err := c.WaitForPublication(short, repo, before, true, time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while the index is unchanged, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	changed = true
+	if err := c.WaitForPublication(ctx, repo, before, true, time.Millisecond); err != nil {
+		t.Errorf("Error: %s", err)
+	}
+
+	successful, changed = false, false
+	if err := c.WaitForPublication(ctx, repo, before, true, time.Millisecond); err == nil || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected failed publication, got %v", err)
+	}
+}
+
+func TestWaitForPublicationErrors(t *testing.T) {
+	var status int
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/api/") && status != 0 {
+			rw.WriteHeader(status)
+			return
+		}
+		if strings.HasPrefix(req.URL.Path, "/api/") {
+			rw.Write([]byte(`{ "status": "SUCCESS", "code": 200, "data": { "id": 2, "name": "testrepo1" } }`))
+			return
+		}
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := model.Repository{Id: 2, Name: "testrepo1", Technology: "R", PublicationUri: server.URL + "/repo/testrepo1"}
+	c := New(WithBaseURL(server.URL), WithHTTPClient(server.Client()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// rejected API requests and invalid publication URIs end the wait
+	status = http.StatusUnauthorized
+	var apiErr *APIError
+	if err := c.WaitForPublication(ctx, repo, IndexSnapshot{}, true, time.Millisecond); !errors.As(err, &apiErr) {
+		t.Errorf("Expected API error, got %v", err)
+	}
+
+	status = 0
+	invalid := repo
+	invalid.PublicationUri = "ftp:/testrepo1"
+	var indexErr *IndexError
+	if err := c.WaitForPublication(ctx, invalid, IndexSnapshot{}, true, time.Millisecond); !errors.As(err, &indexErr) {
+		t.Errorf("Expected index error, got %v", err)
+	}
+
+	// other errors are reported when waiting stops
+	closed := repo
+	closed.PublicationUri = "http://127.0.0.1:1/repo/testrepo1"
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	err := c.WaitForPublication(short, closed, IndexSnapshot{}, true, time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "127.0.0.1:1") {
+		t.Errorf("Expected deadline exceeded with the last error, got %v", err)
+	}
+}
+
+func TestGetRepositoryIndexOtherHost(t *testing.T) {
+	var authorization string
+
+	index := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		authorization = req.Header.Get("Authorization")
+		rw.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer index.Close()
+
+	repo := model.Repository{Name: "testrepo1", Technology: "Python", PublicationUri: index.URL + "/repo/testrepo1", RequiresAuthentication: true}
+	c := New(WithBaseURL("http://rdepot.example.org"), WithBasicAuth("user", "token"), WithHTTPClient(index.Client()))
+
+	snapshot, err := c.GetRepositoryIndex(context.Background(), repo)
+	if err == nil || !strings.Contains(err.Error(), "credentials are only sent") {
+		t.Errorf("Expected error about credentials, got %v", err)
+	}
+	if snapshot.Served() {
+		t.Errorf("Expected the index not to be served")
+	}
+	expectEqual(t, "", authorization)
+}
@@ -0,0 +1,206 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// An http.RoundTripper that retries requests failing with a transient network
+// error or status (429, 502, 503, 504). Waits between attempts grow
+// exponentially with jitter, unless the server asks for a delay with
+// Retry-After; a delay beyond MaxBackoff or the deadline is not waited for.
+// Only idempotent requests are retried: GET, HEAD, OPTIONS, PUT, DELETE and
+// requests marked with Idempotent, such as patches that only replace values.
+// Set RetryNonIdempotent to retry any request.
+type RetryTransport struct {
+	Base               http.RoundTripper
+	MaxRetries         int
+	MinBackoff         time.Duration
+	MaxBackoff         time.Duration
+	RetryNonIdempotent bool
+	Logger             *log.Logger
+}
+
+// A RetryTransport on top of base with the default retry policy
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+	}
+}
+
+type idempotentKey struct{}
+
+// Mark the requests made with ctx as safe to retry whatever their method
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if !t.retryable(req) {
+		return base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := base.RoundTrip(req)
+		if attempt >= t.MaxRetries || !transient(res, err) || req.Context().Err() != nil {
+			return res, err
+		}
+
+		wait := t.backoff(attempt)
+		if res != nil {
+			if after, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+				// a server asking for a longer wait than the backoff allows, or
+				// than is left before the deadline, gets its answer right away
+				if !t.canWait(req.Context(), after) {
+					return res, err
+				}
+				wait = after
+			}
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+		if t.Logger != nil {
+			if err != nil {
+				t.Logger.Printf("%s %s: %v, retrying in %s", req.Method, req.URL.Redacted(), err, wait.Round(time.Millisecond))
+			} else {
+				t.Logger.Printf("%s %s: %s, retrying in %s", req.Method, req.URL.Redacted(), res.Status, wait.Round(time.Millisecond))
+			}
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *RetryTransport) retryable(req *http.Request) bool {
+	if t.MaxRetries <= 0 || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return false
+	}
+	if t.RetryNonIdempotent {
+		return true
+	}
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	idempotent, _ := req.Context().Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+// Wait before retry number attempt+1, chosen at random between half and all
+// of the exponential backoff
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	wait := t.MinBackoff
+	for i := 0; i < attempt && wait < t.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if t.MaxBackoff > 0 && wait > t.MaxBackoff {
+		wait = t.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// Whether a wait fits within the maximum backoff and the deadline of ctx
+func (t *RetryTransport) canWait(ctx context.Context, wait time.Duration) bool {
+	if t.MaxBackoff > 0 && wait > t.MaxBackoff {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return false
+	}
+	return true
+}
+
+// A failed attempt that may succeed when it is repeated: a timeout, a
+// connection dropped by the server or a transient status. Errors such as an
+// unknown host, a refused connection or an invalid certificate are returned
+// right away.
+func transient(res *http.Response, err error) bool {
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true
+		}
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+			errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+	}
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Parse a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// Copy of a request with a fresh body for another attempt
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, nil
+}
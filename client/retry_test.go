@@ -0,0 +1,174 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var tests = []struct {
+		method             string
+		body               string
+		idempotent         bool
+		retryNonIdempotent bool
+		requests           int
+		status             int
+	}{
+		{method: "GET", requests: 3, status: http.StatusOK},
+		{method: "DELETE", requests: 3, status: http.StatusOK},
+		{method: "PATCH", body: "[]", requests: 1, status: http.StatusServiceUnavailable},
+		{method: "PATCH", body: "[]", idempotent: true, requests: 3, status: http.StatusOK},
+		{method: "POST", body: "payload", requests: 1, status: http.StatusServiceUnavailable},
+		{method: "POST", body: "payload", retryNonIdempotent: true, requests: 3, status: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		var requests int
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			requests++
+			body, _ := io.ReadAll(req.Body)
+			expectEqual(t, test.body, string(body))
+			if requests < 3 {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			rw.WriteHeader(http.StatusOK)
+		}))
+
+		transport := NewRetryTransport(server.Client().Transport)
+		transport.MinBackoff = time.Millisecond
+		transport.RetryNonIdempotent = test.retryNonIdempotent
+		httpClient := &http.Client{Transport: transport}
+
+		ctx := context.Background()
+		if test.idempotent {
+			ctx = Idempotent(ctx)
+		}
+		var body io.Reader
+		if test.body != "" {
+			body = strings.NewReader(test.body)
+		}
+		req, err := http.NewRequestWithContext(ctx, test.method, server.URL, body)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+
+		res, err := httpClient.Do(req)
+		if err != nil {
+			t.Fatalf("Error: %s", err)
+		}
+		res.Body.Close()
+
+		expectEqual(t, test.status, res.StatusCode)
+		expectEqual(t, test.requests, requests)
+		server.Close()
+	}
+}
+
+func TestRetryTransportGivesUp(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Set("Retry-After", "0")
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	transport := NewRetryTransport(server.Client().Transport)
+	transport.MinBackoff = time.Hour
+	c := New(WithBaseURL(server.URL), WithHTTPClient(&http.Client{Transport: transport}))
+
+	_, err := c.ListRepositories(context.Background(), "")
+	if err == nil {
+		t.Fatalf("Expected error after the retries are exhausted")
+	}
+	expectEqual(t, 1+DefaultMaxRetries, requests)
+}
+
+func TestRetryAfter(t *testing.T) {
+	var tests = []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "5", wait: 5 * time.Second, ok: true},
+		{value: "-1", ok: false},
+		{value: "soon", ok: false},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", wait: 0, ok: true},
+	}
+
+	for _, test := range tests {
+		wait, ok := retryAfter(test.value)
+		expectEqual(t, test.ok, ok)
+		expectEqual(t, test.wait, wait)
+	}
+}
+
+func TestIdempotentPatch(t *testing.T) {
+	expectEqual(t, true, idempotentPatch([]PatchOperation{{Op: "replace", Path: "/deleted", Value: true}}))
+	expectEqual(t, false, idempotentPatch([]PatchOperation{{Op: "replace", Path: "/deleted", Value: true}, {Op: "add", Path: "/tags/-", Value: "x"}}))
+}
+
+func TestRetryAfterBeyondMaxBackoff(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+		rw.Header().Set("Retry-After", "86400")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport := NewRetryTransport(server.Client().Transport)
+	res, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	res.Body.Close()
+	expectEqual(t, http.StatusServiceUnavailable, res.StatusCode)
+	expectEqual(t, 1, requests)
+}
+
+func TestTransient(t *testing.T) {
+	var tests = []struct {
+		err       error
+		transient bool
+	}{
+		{err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}, transient: true},
+		{err: io.ErrUnexpectedEOF, transient: true},
+		{err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}, transient: true},
+		{err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, transient: false},
+		{err: &net.DNSError{Err: "no such host", IsNotFound: true}, transient: false},
+		{err: x509.UnknownAuthorityError{}, transient: false},
+		{err: errors.New("unsupported protocol scheme"), transient: false},
+	}
+
+	for _, test := range tests {
+		expectEqual(t, test.transient, transient(nil, test.err))
+	}
+}
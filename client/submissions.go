@@ -0,0 +1,131 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+// List submissions, optionally filtering by state, repository name and
+// submitter login. The state and repository filters are applied by the
+// server, the submitter login is only checked here.
+func (c *Client) ListSubmissions(ctx context.Context, state string, repository string, submitter string) ([]model.Submission, error) {
+	path, err := technologyToPath(c.technology)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{}
+	if state != "" {
+		q.Add("state", strings.ToUpper(state))
+	}
+	if repository != "" {
+		q.Add("repository", repository)
+	}
+	submissions, err := listPages[model.Submission](ctx, c, "/api/v2/manager/"+path+"submissions", q)
+	if err != nil {
+		return nil, err
+	}
+
+	// servers ignoring a filter return more than asked for, check again and
+	// drop submissions lacking the filtered field only when filtering
+	filtered := make([]model.Submission, 0, len(submissions))
+	for _, s := range submissions {
+		if state != "" && !strings.EqualFold(s.State, state) {
+			continue
+		}
+		if repository != "" && (s.Package == nil || s.Package.Repository.Name != repository) {
+			continue
+		}
+		if submitter != "" && (s.Submitter == nil || s.Submitter.Login != submitter) {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	return filtered, nil
+}
+
+func (c *Client) GetSubmission(ctx context.Context, id int) (model.Submission, error) {
+	path, err := technologyToPath(c.technology)
+	if err != nil {
+		return model.Submission{}, err
+	}
+
+	return getEntity[model.Submission](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"submissions/%d", id))
+}
+
+func (c *Client) PatchSubmission(ctx context.Context, submission model.Submission, patch []PatchOperation) (model.Submission, error) {
+	technology := submission.Technology
+	if technology == "" && submission.Package != nil {
+		technology = submission.Package.Technology
+	}
+	if technology == "" {
+		technology = c.technology
+	}
+	path, err := technologyToPath(strings.ToLower(technology))
+	if err != nil {
+		return model.Submission{}, err
+	}
+	if path == "" {
+		return model.Submission{}, fmt.Errorf("invalid technology provided for patching only Python and R are supported")
+	}
+
+	return patchEntity[model.Submission](ctx, c, fmt.Sprintf("/api/v2/manager/"+path+"submissions/%d", submission.Id), patch)
+}
+
+func (c *Client) AcceptSubmission(ctx context.Context, submission model.Submission) (model.Submission, error) {
+	return c.PatchSubmission(ctx, submission, []PatchOperation{
+		{Op: "replace", Path: "/state", Value: model.SubmissionAccepted},
+	})
+}
+
+func (c *Client) RejectSubmission(ctx context.Context, submission model.Submission, reason string) (model.Submission, error) {
+	patch := []PatchOperation{
+		{Op: "replace", Path: "/state", Value: model.SubmissionRejected},
+	}
+	if reason != "" {
+		patch = append(patch, PatchOperation{Op: "replace", Path: "/rejectReason", Value: reason})
+	}
+	return c.PatchSubmission(ctx, submission, patch)
+}
+
+func (c *Client) CancelSubmission(ctx context.Context, submission model.Submission) (model.Submission, error) {
+	return c.PatchSubmission(ctx, submission, []PatchOperation{
+		{Op: "replace", Path: "/state", Value: model.SubmissionCancelled},
+	})
+}
+
+// Poll a submission until it leaves the waiting state and return it.
+// Polling stops when ctx is done.
+func (c *Client) WaitForSubmission(ctx context.Context, id int, interval time.Duration) (model.Submission, error) {
+	for {
+		submission, err := c.GetSubmission(ctx, id)
+		if err != nil {
+			return submission, err
+		}
+		if submission.State != model.SubmissionWaiting {
+			return submission, nil
+		}
+		if err := sleep(ctx, interval); err != nil {
+			return submission, fmt.Errorf("stopped waiting for %s: %w", submission.Summary(), err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+var submissionsBody = []byte(`{ "status": "SUCCESS", "code": 200, "message": "Your request has been processed successfully.", "messageCode": "success.request.processed", "data": { "links": [], "content": [ { "id": 5, "state": "WAITING", "technology": "R", "package": { "id": 8, "name": "accrued", "version": "1.2", "repository": { "id": 3, "name": "testrepo2" } }, "submitter": { "id": 4, "name": "Albert Einstein", "login": "einstein" } }, { "id": 6, "state": "WAITING", "technology": "R", "package": { "id": 9, "name": "oaColors", "version": "0.0.4", "repository": { "id": 2, "name": "testrepo1" } }, "submitter": { "id": 5, "name": "Nikola Tesla", "login": "tesla" } } ], "page": { "size": 2, "totalElements": 2, "totalPages": 1, "number": 0 } }}`)
+
+func TestListSubmissions(t *testing.T) {
+
+	var tests = []struct {
+		repository   string
+		submitter    string
+		nSubmissions int
+	}{
+		{repository: "", submitter: "", nSubmissions: 2},
+		{repository: "testrepo1", submitter: "", nSubmissions: 1},
+		{repository: "", submitter: "einstein", nSubmissions: 1},
+		{repository: "testrepo1", submitter: "einstein", nSubmissions: 0},
+	}
+
+	for _, test := range tests {
+
+		server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			expectEqual(t, "/api/v2/manager/r/submissions", req.URL.Path)
+			expectEqual(t, "WAITING", req.URL.Query().Get("state"))
+			expectEqual(t, test.repository, req.URL.Query().Get("repository"))
+			rw.Write(submissionsBody)
+		}))
+		defer server.Close()
+
+		c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+		res, err := c.ListSubmissions(context.Background(), "waiting", test.repository, test.submitter)
+
+		if err != nil {
+			t.Errorf("Got error: %s", err)
+		}
+
+		if len(res) != test.nSubmissions {
+			t.Errorf("Expected %d submissions, got %d", test.nSubmissions, len(res))
+		}
+	}
+}
+
+func TestListSubmissionsWithoutPackage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Write([]byte(`{"status": "SUCCESS", "code": 200, "data": {"content": [{"id": 5, "state": "WAITING"}], "page": {"totalPages": 1}}}`))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+	res, err := c.ListSubmissions(context.Background(), "", "", "")
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if len(res) != 1 {
+		t.Errorf("Expected 1 submission, got %d", len(res))
+	}
+
+	res, err = c.ListSubmissions(context.Background(), "", "testrepo1", "einstein")
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if len(res) != 0 {
+		t.Errorf("Expected 0 submissions, got %d", len(res))
+	}
+}
+
+func TestRejectSubmission(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "PATCH", req.Method)
+		expectEqual(t, "/api/v2/manager/python/submissions/5", req.URL.Path)
+		body, _ := io.ReadAll(req.Body)
+		expectEqual(t, `[{"op":"replace","path":"/state","value":"REJECTED"},{"op":"replace","path":"/rejectReason","value":"missing license"}]`, string(body))
+		rw.Write([]byte(`{"status": "SUCCESS", "code": 200, "data": {"id": 5, "state": "REJECTED", "technology": "Python"}}`))
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("all"), WithHTTPClient(server.Client()))
+
+	submission, err := c.RejectSubmission(context.Background(), model.Submission{Id: 5, State: "WAITING", Technology: "Python"}, "missing license")
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, model.SubmissionRejected, submission.State)
+}
+
+func TestWaitForSubmission(t *testing.T) {
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		expectEqual(t, "/api/v2/manager/r/submissions/5", req.URL.Path)
+		requests++
+		if requests < 3 {
+			rw.Write([]byte(`{"status": "SUCCESS", "code": 200, "data": {"id": 5, "state": "WAITING"}}`))
+		} else {
+			rw.Write([]byte(`{"status": "SUCCESS", "code": 200, "data": {"id": 5, "state": "REJECTED", "rejectReason": "missing license"}}`))
+		}
+	}))
+	defer server.Close()
+
+	c := New(WithBaseURL(server.URL), WithBasicAuth("", "validtoken"), WithTechnology("r"), WithHTTPClient(server.Client()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	submission, err := c.WaitForSubmission(ctx, 5, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	expectEqual(t, model.SubmissionRejected, submission.State)
+	expectEqual(t, "missing license", submission.RejectReason)
+	expectEqual(t, 3, requests)
+}
@@ -0,0 +1,88 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// TLS settings of the connection to the RDepot server
+type TLSOptions struct {
+	// PEM file with certificates of authorities trusted in addition to the
+	// ones of the system, e.g. an internal CA
+	CACert string
+	// PEM files with the client certificate and its private key for mutual
+	// TLS. The key may be left empty when it is in the certificate file.
+	ClientCert string
+	ClientKey  string
+	// Do not verify the certificate of the server. Only for testing, the
+	// connection is open to man-in-the-middle attacks.
+	InsecureSkipVerify bool
+}
+
+// TLS configuration with the certificates of the options loaded
+func (o TLSOptions) Config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CACert != "" {
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificate: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", o.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.ClientKey != "" && o.ClientCert == "" {
+		return nil, fmt.Errorf("client key given without client certificate")
+	}
+	if o.ClientCert != "" {
+		key := o.ClientKey
+		if key == "" {
+			key = o.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(o.ClientCert, key)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// Transport with the settings of http.DefaultTransport and the TLS options
+func NewTransport(o TLSOptions) (*http.Transport, error) {
+	config, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Write a PEM block to a file in dir
+func writePEM(t *testing.T, dir string, name string, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	return path
+}
+
+// Self-signed client certificate and key files
+func clientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rdepot-cli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	return cert, writePEM(t, dir, "client.crt", "CERTIFICATE", der), writePEM(t, dir, "client.key", "EC PRIVATE KEY", keyDER)
+}
+
+func TestTLSOptions(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := clientCertificate(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := writePEM(t, dir, "ca.crt", "CERTIFICATE", server.Certificate().Raw)
+
+	var tests = []struct {
+		name    string
+		options TLSOptions
+		ok      bool
+	}{
+		{name: "no CA", options: TLSOptions{ClientCert: certFile, ClientKey: keyFile}},
+		{name: "no client certificate", options: TLSOptions{CACert: caFile}},
+		{name: "CA and client certificate", options: TLSOptions{CACert: caFile, ClientCert: certFile, ClientKey: keyFile}, ok: true},
+		{name: "insecure", options: TLSOptions{ClientCert: certFile, ClientKey: keyFile, InsecureSkipVerify: true}, ok: true},
+	}
+
+	for _, test := range tests {
+		transport, err := NewTransport(test.options)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		httpClient := &http.Client{Transport: transport}
+		res, err := httpClient.Get(server.URL)
+		if err == nil {
+			res.Body.Close()
+		}
+		if test.ok && err != nil {
+			t.Errorf("%s: Error: %s", test.name, err)
+		} else if !test.ok && err == nil {
+			t.Errorf("%s: Expected TLS error", test.name)
+		}
+	}
+}
+
+func TestTLSOptionsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	_, certFile, keyFile := clientCertificate(t, dir)
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+
+	var tests = []TLSOptions{
+		{CACert: filepath.Join(dir, "missing.crt")},
+		{CACert: notPEM},
+		{ClientKey: keyFile},
+		{ClientCert: certFile},
+		{ClientCert: certFile, ClientKey: certFile},
+	}
+
+	for _, test := range tests {
+		if _, err := test.Config(); err == nil {
+			t.Errorf("Expected error for %+v", test)
+		}
+	}
+}
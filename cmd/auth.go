@@ -0,0 +1,118 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"openanalytics.eu/rdepot/cli/client"
+	"openanalytics.eu/rdepot/cli/credentials"
+)
+
+const authMethods = "basic, bearer or oidc"
+
+func checkAuth(auth string) error {
+	switch auth {
+	case "basic", "bearer", "oidc":
+		return nil
+	default:
+		return fmt.Errorf("invalid authentication %s, use %s", auth, authMethods)
+	}
+}
+
+// Authenticator for the --auth flag, nil for basic authentication which the
+// client sets up from the configuration
+func authenticator() (client.Authenticator, error) {
+	auth := viper.GetString("auth")
+	if err := checkAuth(auth); err != nil {
+		return nil, err
+	}
+
+	switch auth {
+	case "bearer":
+		if Config.Token != "" {
+			return client.BearerAuth{Token: Config.Token}, nil
+		}
+		return &client.LazyAuth{
+			New: func() (client.Authenticator, error) {
+				_, token, err := storedCredentials()
+				return client.BearerAuth{Token: token}, err
+			},
+		}, nil
+	case "oidc":
+		return oidcAuth()
+	default:
+		return nil, nil
+	}
+}
+
+// OpenID Connect authentication with the tokens of 'rdepot login' for the
+// configured host
+func oidcAuth() (*client.OIDCAuth, error) {
+	issuer := viper.GetString("oidc-issuer")
+	if issuer == "" {
+		return nil, fmt.Errorf("no OpenID Connect issuer, set it with --oidc-issuer")
+	}
+	httpClient, err := httpClient()
+	if err != nil {
+		return nil, err
+	}
+	return &client.OIDCAuth{
+		Issuer:     issuer,
+		ClientID:   viper.GetString("oidc-client-id"),
+		Scopes:     strings.Fields(viper.GetString("oidc-scopes")),
+		HTTPClient: httpClient,
+		Cache:      storeTokenCache{key: credentialKey("oidc")},
+	}, nil
+}
+
+// Caches the tokens of the identity provider in the credential store
+type storeTokenCache struct {
+	key string
+}
+
+func (c storeTokenCache) Load() (*client.OIDCToken, error) {
+	store, err := credentialStore()
+	if err != nil {
+		return nil, err
+	}
+	cred, err := store.Get(c.key)
+	if errors.Is(err, credentials.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &client.OIDCToken{
+		AccessToken:  cred.Token,
+		RefreshToken: cred.RefreshToken,
+		Expiry:       cred.Expiry,
+	}, nil
+}
+
+func (c storeTokenCache) Save(token *client.OIDCToken) error {
+	store, err := credentialStore()
+	if err != nil {
+		return err
+	}
+	return store.Set(c.key, credentials.Credential{
+		Token:        token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+	})
+}
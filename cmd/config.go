@@ -0,0 +1,228 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the configuration file",
+	Long: `Manage the configuration file with named contexts.
+
+A context holds the settings to talk to one RDepot instance. The current
+context is used unless another one is selected with --context or the
+RDEPOT_CONTEXT environment variable. The file is read from --config,
+RDEPOT_CONFIG or the user configuration directory, e.g.
+~/.config/rdepot/config.yaml on Linux.
+
+A setting given as a flag takes precedence over its RDEPOT_* environment
+variable, which takes precedence over the context, which takes precedence
+over the flag default.`,
+	// the configuration commands work on the file itself and do not need
+	// a client, nor a valid context
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return checkOutput()
+	},
+	Run: func(cmd *cobra.Command, args []string) {},
+}
+
+// Settings of a context in the configuration file
+type configContext struct {
+	Host         string `yaml:"host,omitempty"`
+	Token        string `yaml:"token,omitempty"`
+	Username     string `yaml:"username,omitempty"`
+	Technology   string `yaml:"technology,omitempty"`
+	Auth         string `yaml:"auth,omitempty"`
+	OIDCIssuer   string `yaml:"oidc-issuer,omitempty"`
+	OIDCClientID string `yaml:"oidc-client-id,omitempty"`
+	OIDCScopes   string `yaml:"oidc-scopes,omitempty"`
+	CACert       string `yaml:"ca-cert,omitempty"`
+	ClientCert   string `yaml:"client-cert,omitempty"`
+	ClientKey    string `yaml:"client-key,omitempty"`
+	Insecure     string `yaml:"insecure-skip-verify,omitempty"`
+}
+
+type configFile struct {
+	CurrentContext string                    `yaml:"current-context,omitempty"`
+	Contexts       map[string]*configContext `yaml:"contexts,omitempty"`
+}
+
+// Keys of the settings a context can hold, named like the global flags
+var configKeys = []string{"host", "token", "username", "technology", "auth", "oidc-issuer", "oidc-client-id", "oidc-scopes",
+	"ca-cert", "client-cert", "client-key", "insecure-skip-verify"}
+
+func (c *configContext) fields() map[string]*string {
+	return map[string]*string{
+		"host":                 &c.Host,
+		"token":                &c.Token,
+		"username":             &c.Username,
+		"technology":           &c.Technology,
+		"auth":                 &c.Auth,
+		"oidc-issuer":          &c.OIDCIssuer,
+		"oidc-client-id":       &c.OIDCClientID,
+		"oidc-scopes":          &c.OIDCScopes,
+		"ca-cert":              &c.CACert,
+		"client-cert":          &c.ClientCert,
+		"client-key":           &c.ClientKey,
+		"insecure-skip-verify": &c.Insecure,
+	}
+}
+
+func (c *configContext) set(key string, value string) error {
+	field, ok := c.fields()[key]
+	if !ok {
+		return fmt.Errorf("unknown setting %s, use one of %v", key, configKeys)
+	}
+
+	if value != "" {
+		switch key {
+		case "technology":
+			var technology TechnologyEnum
+			if err := technology.Set(value); err != nil {
+				return fmt.Errorf("invalid technology %s: %w", value, err)
+			}
+		case "auth":
+			if err := checkAuth(value); err != nil {
+				return err
+			}
+		case "insecure-skip-verify":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid value %s for %s, use true or false", value, key)
+			}
+		}
+	}
+	*field = value
+	return nil
+}
+
+// Settings of a context as viper configuration, empty settings are left out
+func (c *configContext) settings() map[string]interface{} {
+	settings := map[string]interface{}{}
+	for key, field := range c.fields() {
+		if *field != "" {
+			settings[key] = *field
+		}
+	}
+	return settings
+}
+
+func (f *configFile) contextNames() []string {
+	names := make([]string, 0, len(f.Contexts))
+	for name := range f.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Path of the configuration file
+func configPath() (string, error) {
+	if path := viper.GetString("config"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rdepot", "config.yaml"), nil
+}
+
+// Read the configuration file, a missing file is an empty configuration
+func loadConfigFile() (*configFile, error) {
+	file := &configFile{Contexts: map[string]*configContext{}}
+
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return file, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, file); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %w", path, err)
+	}
+	if file.Contexts == nil {
+		file.Contexts = map[string]*configContext{}
+	}
+	for name, context := range file.Contexts {
+		if context == nil {
+			file.Contexts[name] = &configContext{}
+		}
+	}
+	return file, nil
+}
+
+// Write the configuration file, readable by the user only since it may
+// contain tokens
+func (f *configFile) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Name of the context selected with --context, or the current context
+func (f *configFile) selectedContext() string {
+	if name := viper.GetString("context"); name != "" {
+		return name
+	}
+	return f.CurrentContext
+}
+
+// Make the settings of the selected context available through viper, below
+// flags and environment variables
+func applyConfigFile() error {
+	file, err := loadConfigFile()
+	if err != nil {
+		return err
+	}
+	name := file.selectedContext()
+	if name == "" {
+		return nil
+	}
+	context, ok := file.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context not found: %s", name)
+	}
+	return viper.MergeConfigMap(context.settings())
+}
@@ -0,0 +1,78 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configGetContextsCmd)
+}
+
+// A context as listed by get-contexts, without its token
+type contextSummary struct {
+	Current    bool   `json:"current"`
+	Name       string `json:"name"`
+	Host       string `json:"host,omitempty"`
+	Username   string `json:"username,omitempty"`
+	Technology string `json:"technology,omitempty"`
+}
+
+func (c contextSummary) Header(wide bool) []string {
+	return []string{"CURRENT", "NAME", "HOST", "USERNAME", "TECHNOLOGY"}
+}
+
+func (c contextSummary) Row(wide bool) []string {
+	current := ""
+	if c.Current {
+		current = "*"
+	}
+	return []string{current, c.Name, c.Host, c.Username, c.Technology}
+}
+
+var configGetContextsCmd = &cobra.Command{
+	Use:   "get-contexts",
+	Short: "List the contexts",
+	Long:  `List the contexts of the configuration file`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+
+		summaries := make([]contextSummary, 0, len(file.Contexts))
+		for _, name := range file.contextNames() {
+			context := file.Contexts[name]
+			summaries = append(summaries, contextSummary{
+				Current:    name == file.CurrentContext,
+				Name:       name,
+				Host:       context.Host,
+				Username:   context.Username,
+				Technology: context.Technology,
+			})
+		}
+
+		if out, err := formatOutput(summaries); err != nil {
+			return err
+		} else {
+			fmt.Print(out)
+			return nil
+		}
+	},
+}
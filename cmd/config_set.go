@@ -0,0 +1,66 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting of a context",
+	Long: `Change a setting of the context selected with --context, or of the
+current context. Without either the context is named 'default'. The context
+is created when it does not exist yet and becomes the current context when
+there is none. An empty value removes the setting.
+
+Keys: host, token, username, technology, auth, oidc-issuer, oidc-client-id,
+oidc-scopes, ca-cert, client-cert, client-key and insecure-skip-verify`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+
+		name := file.selectedContext()
+		if name == "" {
+			name = "default"
+		}
+		context, ok := file.Contexts[name]
+		if !ok {
+			context = &configContext{}
+			file.Contexts[name] = context
+		}
+		if err := context.set(args[0], args[1]); err != nil {
+			return err
+		}
+		if file.CurrentContext == "" {
+			file.CurrentContext = name
+		}
+
+		if err := file.save(); err != nil {
+			return err
+		}
+		fmt.Printf("set %s of context %s\n", args[0], name)
+		return nil
+	},
+}
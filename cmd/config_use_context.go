@@ -0,0 +1,48 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configCmd.AddCommand(configUseContextCmd)
+}
+
+var configUseContextCmd = &cobra.Command{
+	Use:   "use-context <name>",
+	Short: "Set the current context",
+	Long:  `Set the current context`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := loadConfigFile()
+		if err != nil {
+			return err
+		}
+		if _, ok := file.Contexts[args[0]]; !ok {
+			return fmt.Errorf("context not found: %s", args[0])
+		}
+
+		file.CurrentContext = args[0]
+		if err := file.save(); err != nil {
+			return err
+		}
+		fmt.Printf("switched to context %s\n", args[0])
+		return nil
+	},
+}
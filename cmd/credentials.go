@@ -0,0 +1,106 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+
+	"openanalytics.eu/rdepot/cli/credentials"
+)
+
+// Store for the tokens of 'rdepot login': the keyring of the operating
+// system, or a file encrypted with a passphrase next to the configuration
+// file when there is no keyring
+func credentialStore() (credentials.Store, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+
+	var once sync.Once
+	var passphrase string
+	var passphraseErr error
+	return credentials.FallbackStore{
+		Primary: credentials.NewKeyringStore(),
+		Fallback: credentials.FileStore{
+			Path: filepath.Join(filepath.Dir(path), "credentials"),
+			Passphrase: func() (string, error) {
+				once.Do(func() {
+					passphrase, passphraseErr = credentialsPassphrase()
+				})
+				return passphrase, passphraseErr
+			},
+		},
+	}, nil
+}
+
+// Passphrase of the credentials file from RDEPOT_CREDENTIALS_PASSPHRASE or
+// asked for on the terminal
+func credentialsPassphrase() (string, error) {
+	if passphrase := os.Getenv("RDEPOT_CREDENTIALS_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("no keyring available, set RDEPOT_CREDENTIALS_PASSPHRASE to use the encrypted credentials file")
+	}
+	return readSecret("Passphrase for the credentials file: ")
+}
+
+// Key of the credentials of the configured host for an authentication method,
+// so that logging in with one method does not overwrite the tokens of another
+func credentialKey(auth string) string {
+	return auth + ":" + Config.Host
+}
+
+// Credentials stored by 'rdepot login' for the configured host, none when the
+// user did not log in
+func storedCredentials() (string, string, error) {
+	store, err := credentialStore()
+	if err != nil {
+		return "", "", err
+	}
+	cred, err := store.Get(credentialKey(viper.GetString("auth")))
+	if errors.Is(err, credentials.ErrNotFound) {
+		return "", "", nil
+	}
+	return cred.Username, cred.Token, err
+}
+
+// Read a secret without echo on a terminal, or a line from standard input
+// when it is not a terminal
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		secret, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return strings.TrimSpace(string(secret)), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("could not read from standard input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"openanalytics.eu/rdepot/cli/client"
+)
+
+const (
+	ExitFailure        = 1
+	ExitPartialFailure = 2
+	ExitUnauthorized   = 3
+	ExitNotFound       = 4
+	ExitConflict       = 5
+	ExitInvalid        = 6
+	ExitInterrupted    = 130
+)
+
+// An error that terminates rdepot with a specific exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Exit code to terminate rdepot with after a command returned an error
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		return apiExitCode(apiErr)
+	}
+	var notFoundErr *client.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return ExitNotFound
+	}
+	if errors.Is(err, client.ErrLoginRequired) {
+		return ExitUnauthorized
+	}
+	return ExitFailure
+}
+
+// Map the message code of an API error, or its HTTP status when the message
+// code is not recognized, to an exit code
+func apiExitCode(err *client.APIError) int {
+	code := strings.ToLower(err.MessageCode)
+	switch {
+	case strings.Contains(code, "unauthorized"), strings.Contains(code, "not.authorized"),
+		strings.Contains(code, "forbidden"), strings.Contains(code, "access.denied"):
+		return ExitUnauthorized
+	case strings.Contains(code, "not.found"), strings.Contains(code, "notfound"):
+		return ExitNotFound
+	case strings.Contains(code, "duplicate"), strings.Contains(code, "already.exists"), strings.Contains(code, "conflict"):
+		return ExitConflict
+	case strings.Contains(code, "validation"), strings.Contains(code, "invalid"):
+		return ExitInvalid
+	}
+
+	switch err.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ExitUnauthorized
+	case http.StatusNotFound:
+		return ExitNotFound
+	case http.StatusConflict:
+		return ExitConflict
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ExitInvalid
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"openanalytics.eu/rdepot/cli/client"
+	"openanalytics.eu/rdepot/cli/credentials"
+)
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store a token for an RDepot host",
+	Long: `Store a token for an RDepot host.
+
+The token is asked for without echo, or read from standard input when it is
+not a terminal, e.g. 'rdepot login < token.txt'. It is checked against the
+server and stored in the keyring of the operating system. Without keyring,
+e.g. on a headless Linux server, it is stored in a file encrypted with a
+passphrase, which is asked for or read from RDEPOT_CREDENTIALS_PASSPHRASE.
+
+Commands use the stored token of their host when no token is given with
+--token or RDEPOT_TOKEN. Tokens are stored per host and --auth method, so
+logging in with one method keeps the tokens of the others.
+
+With --auth oidc no token is asked for: the device authorization flow of the
+identity provider at --oidc-issuer is started and the user logs in with the
+browser at the URL shown. The access and refresh tokens are stored, and
+refreshed by commands when the access token expired.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if viper.GetString("auth") == "oidc" {
+			return oidcLogin()
+		}
+
+		prompt := "Token: "
+		if Config.Username == "" && viper.GetString("auth") == "basic" {
+			prompt = "Token (username:token): "
+		}
+		token, err := readSecret(prompt)
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return fmt.Errorf("no token given")
+		}
+
+		cfg := Config
+		cfg.Token = token
+		opts, err := clientOptions()
+		if err != nil {
+			return err
+		}
+		if viper.GetString("auth") == "bearer" {
+			opts = append(opts, client.WithBearerToken(token))
+		}
+		if err := client.NewFromConfig(cfg, opts...).CheckCredentials(commandCtx); err != nil {
+			return fmt.Errorf("could not log in to %s: %w", Config.Host, err)
+		}
+
+		store, err := credentialStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Set(credentialKey(viper.GetString("auth")), credentials.Credential{Username: Config.Username, Token: token}); err != nil {
+			return fmt.Errorf("could not store the token: %w", err)
+		}
+
+		if viper.GetString("auth") == "bearer" {
+			fmt.Printf("logged in to %s\n", Config.Host)
+			return nil
+		}
+		username := Config.Username
+		if username == "" {
+			username, _, _ = strings.Cut(token, ":")
+		}
+		fmt.Printf("logged in to %s as %s\n", Config.Host, username)
+		return nil
+	},
+}
+
+// Log in with the device authorization flow of the identity provider
+func oidcLogin() error {
+	auth, err := oidcAuth()
+	if err != nil {
+		return err
+	}
+	authorization, err := auth.StartDeviceLogin(commandCtx)
+	if err != nil {
+		return fmt.Errorf("could not start the login at %s: %w", auth.Issuer, err)
+	}
+
+	if authorization.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "Open %s to log in\n", authorization.VerificationURIComplete)
+	} else {
+		fmt.Fprintf(os.Stderr, "Open %s and enter the code %s to log in\n", authorization.VerificationURI, authorization.UserCode)
+	}
+	if _, err := auth.WaitForDeviceToken(commandCtx, authorization); err != nil {
+		return fmt.Errorf("could not log in to %s: %w", auth.Issuer, err)
+	}
+
+	opts, err := clientOptions()
+	if err != nil {
+		return err
+	}
+	if err := client.NewFromConfig(Config, append(opts, client.WithAuthenticator(auth))...).CheckCredentials(commandCtx); err != nil {
+		return fmt.Errorf("could not log in to %s: %w", Config.Host, err)
+	}
+	fmt.Printf("logged in to %s\n", Config.Host)
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"openanalytics.eu/rdepot/cli/credentials"
+)
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token of an RDepot host",
+	Long:  `Remove the token stored by 'rdepot login' for an RDepot host`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := credentialStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(credentialKey(viper.GetString("auth"))); errors.Is(err, credentials.ErrNotFound) {
+			return fmt.Errorf("not logged in to %s", Config.Host)
+		} else if err != nil {
+			return err
+		}
+		fmt.Printf("logged out of %s\n", Config.Host)
+		return nil
+	},
+}
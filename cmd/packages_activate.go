@@ -0,0 +1,88 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	for _, c := range []*cobra.Command{packagesActivateCmd, packagesDeactivateCmd} {
+		c.Flags().StringVar(&nameFilter, "name", "", "filter by name glob pattern")
+		c.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository to filter with")
+		c.Flags().BoolVar(&archivedFilter, "archived", false, "only packages archived in the repository")
+		c.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "do not change anything and just show what would be done")
+		packagesCmd.AddCommand(c)
+	}
+}
+
+var (
+	packagesActivateCmd = &cobra.Command{
+		Use:   "activate",
+		Short: "Activate one or many packages",
+		Long:  `Activate one or many packages, adding them to the repository index again`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPackagesActive(cmd, true)
+		},
+	}
+
+	packagesDeactivateCmd = &cobra.Command{
+		Use:   "deactivate",
+		Short: "Deactivate one or many packages",
+		Long: `Deactivate one or many packages. Inactive packages are left out of the
+repository index without being deleted, until they are activated again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPackagesActive(cmd, false)
+		},
+	}
+)
+
+// Activate or deactivate the packages that are not deleted matching the
+// filters of the command
+func setPackagesActive(cmd *cobra.Command, active bool) error {
+	if archivedFilter && repositoryFilter == "" {
+		return fmt.Errorf(
+			"archived filter can only be used when filtering by repository")
+	}
+	action := "deactivated"
+	if active {
+		action = "activated"
+	}
+
+	query := packageQuery(cmd)
+	notDeleted := false
+	query.Deleted = &notDeleted
+	pkgs, err := Client.ListPackages(commandCtx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range pkgs {
+		switch {
+		case pkg.Active == active:
+			fmt.Printf("already %s: %s\n", action, pkg.Summary())
+		case dryRun:
+			fmt.Printf("would be %s: %s\n", action, pkg.Summary())
+		default:
+			if _, err := Client.SetPackageActive(commandCtx, pkg, active); err != nil {
+				return fmt.Errorf("could not change package (%s): %w", pkg.Summary(), err)
+			}
+			fmt.Printf("%s %s\n", action, pkg.Summary())
+		}
+	}
+	return nil
+}
@@ -18,8 +18,6 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-
-	"openanalytics.eu/rdepot/cli/client"
 )
 
 func init() {
@@ -27,15 +25,20 @@ func init() {
 	packagesDeleteCmd.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository to filter with")
 	packagesDeleteCmd.Flags().BoolVar(&archivedFilter, "archived", false, "only list packages archived in the repository")
 	packagesDeleteCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "do not delete anyhing and just show what would be done")
+	packagesDeleteCmd.Flags().BoolVar(&softDelete, "soft", false, "only mark the packages as deleted, so that they can be restored with 'packages restore'")
 	packagesCmd.AddCommand(packagesDeleteCmd)
 }
 
 var (
 	dryRun            bool
+	softDelete        bool
 	packagesDeleteCmd = &cobra.Command{
 		Use:   "delete",
 		Short: "Delete one or many packages",
-		Long:  `Delete one or many packages`,
+		Long: `Delete one or many packages.
+
+Packages are marked as deleted and then removed. With --soft they are only
+marked as deleted, which can be undone with 'packages restore'.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			if archivedFilter && repositoryFilter == "" {
@@ -43,7 +46,12 @@ var (
 					"archived filter can only be used when filtering by repository")
 			}
 
-			pkgs, err := client.ListPackages(client.DefaultClient(), Config, repositoryFilter, archivedFilter, nameFilter)
+			query := packageQuery(cmd)
+			if softDelete {
+				notDeleted := false
+				query.Deleted = &notDeleted
+			}
+			pkgs, err := Client.ListPackages(commandCtx, query)
 			if err != nil {
 				return err
 			}
@@ -52,11 +60,18 @@ var (
 				for _, pkg := range pkgs {
 					fmt.Printf("would be deleted: %s\n", pkg.Summary())
 				}
+			} else if softDelete {
+				for _, pkg := range pkgs {
+					if err := Client.SoftDeletePackage(commandCtx, pkg.Id); err != nil {
+						return fmt.Errorf("could not delete package (%s): %w", pkg.Summary(), err)
+					}
+					fmt.Printf("soft deleted %s\n", pkg.Summary())
+				}
 			} else {
 				for _, pkg := range pkgs {
-					err := client.DeletePackage(client.DefaultClient(), Config, pkg)
+					err := Client.DeletePackage(commandCtx, pkg)
 					if err != nil {
-						return fmt.Errorf("could not delete package (%s): %v", pkg.Summary(), err)
+						return fmt.Errorf("could not delete package (%s): %w", pkg.Summary(), err)
 					} else {
 						fmt.Printf("deleted %s\n", pkg.Summary())
 					}
@@ -0,0 +1,80 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/client"
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func init() {
+	packagesDownloadCmd.Flags().StringVar(&packageVersion, "version", "", "version of the package, the newest version by default")
+	packagesDownloadCmd.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository of the package, needed when it is in several repositories")
+	packagesDownloadCmd.Flags().StringVarP(&downloadDir, "dir", "d", ".", "directory to write the archive to")
+	packagesCmd.AddCommand(packagesDownloadCmd)
+}
+
+var (
+	downloadDir string
+
+	packagesDownloadCmd = &cobra.Command{
+		Use:   "download <name>",
+		Short: "Download the archive of a package",
+		Long: `Download the archive of a package from the publication URI of its
+repository, or from the manager when the repository does not serve it. The
+archive is verified against the MD5 sum of R packages or the hash of Python
+packages before it is written to the directory.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := os.MkdirAll(downloadDir, 0755); err != nil {
+				return err
+			}
+
+			switch Config.Technology {
+			case "r":
+				return downloadPackage(client.FindGenericPackage[model.RPackage](commandCtx, Client, args[0], packageVersion, repositoryFilter))
+			case "python":
+				return downloadPackage(client.FindGenericPackage[model.PythonPackage](commandCtx, Client, args[0], packageVersion, repositoryFilter))
+			case "all":
+				return downloadPackage(client.FindGenericPackage[model.Package](commandCtx, Client, args[0], packageVersion, repositoryFilter))
+			default:
+				return fmt.Errorf("undefined technology %s", Config.Technology)
+			}
+		},
+	}
+)
+
+func downloadPackage[G model.GenericPackage](pkg G, err error) error {
+	if err != nil {
+		return err
+	}
+
+	download, err := Client.DownloadPackage(commandCtx, pkg, downloadDir)
+	if err != nil {
+		return err
+	}
+
+	if out, err := formatOutput(download); err != nil {
+		return err
+	} else {
+		fmt.Print(out)
+		return nil
+	}
+}
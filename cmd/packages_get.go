@@ -0,0 +1,72 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/client"
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func init() {
+	packagesCmd.AddCommand(packagesGetCmd)
+}
+
+var packagesGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Show a single package",
+	Long: `Show a single package with all its details, such as dependencies,
+license and checksum`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid package id %s", args[0])
+		}
+
+		switch Config.Technology {
+		case "r":
+			return printPackage(client.GetGenericPackage[model.RPackage](commandCtx, Client, id))
+		case "python":
+			return printPackage(client.GetGenericPackage[model.PythonPackage](commandCtx, Client, id))
+		case "all":
+			return printPackage(client.GetGenericPackage[model.Package](commandCtx, Client, id))
+		default:
+			return fmt.Errorf("undefined technology %s", Config.Technology)
+		}
+	},
+}
+
+// Print a single package, as a list of its details with table output
+func printPackage[G model.GenericPackage](pkg G, err error) error {
+	if err != nil {
+		return err
+	}
+
+	if detailed, ok := interface{}(pkg).(model.Detailed); ok && (output == "table" || output == "wide") {
+		fmt.Print(string(model.FormatDetails(detailed)))
+		return nil
+	}
+	if out, err := formatOutput(pkg); err != nil {
+		return err
+	} else {
+		fmt.Print(out)
+		return nil
+	}
+}
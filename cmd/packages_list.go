@@ -15,7 +15,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -27,6 +29,8 @@ func init() {
 	packagesListCmd.Flags().StringVar(&nameFilter, "name", "", "filter by name glob pattern")
 	packagesListCmd.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository to filter with")
 	packagesListCmd.Flags().BoolVar(&archivedFilter, "archived", false, "return packages that do not have the latest version in a repository")
+	packagesListCmd.Flags().BoolVar(&deletedFilter, "deleted", false, "only list deleted packages, or with --deleted=false only packages that are not deleted")
+	packagesListCmd.Flags().StringVar(&stateFilter, "state", "", "filter by submission state (waiting, accepted, rejected or cancelled)")
 	packagesCmd.AddCommand(packagesListCmd)
 }
 
@@ -34,6 +38,7 @@ var (
 	nameFilter       string
 	repositoryFilter string
 	archivedFilter   bool
+	deletedFilter    bool
 
 	packagesListCmd = &cobra.Command{
 		Use:   "list",
@@ -45,28 +50,62 @@ var (
 				return fmt.Errorf(
 					"archived filter can only be used when filtering by repository")
 			}
-			var pkgs model.Output
-			var err error
+			if err := checkState(stateFilter); err != nil {
+				return err
+			}
 			switch Config.Technology {
 			case "r":
-				pkgs, err = client.ListGenericPackages[model.RPackage](client.DefaultClient(), Config, repositoryFilter, archivedFilter, nameFilter)
+				return listPackages[model.RPackage](packageQuery(cmd))
 			case "python":
-				pkgs, err = client.ListGenericPackages[model.PythonPackage](client.DefaultClient(), Config, repositoryFilter, archivedFilter, nameFilter)
+				return listPackages[model.PythonPackage](packageQuery(cmd))
 			case "all":
-				pkgs, err = client.ListGenericPackages[model.Package](client.DefaultClient(), Config, repositoryFilter, archivedFilter, nameFilter)
+				return listPackages[model.Package](packageQuery(cmd))
 			default:
 				return fmt.Errorf("undefined technology %s", Config.Technology)
 			}
-			if err != nil {
-				return err
-			}
-
-			if out, err := formatOutput(pkgs); err != nil {
-				return err
-			} else {
-				fmt.Print(out)
-				return nil
-			}
 		},
 	}
 )
+
+// Print the packages decoded as G, with jsonl output each page is printed as
+// soon as it is received
+func listPackages[G model.GenericPackage](query client.PackageQuery) error {
+	pkgs := client.StreamGenericPackages[G](commandCtx, Client, query)
+
+	if output == "jsonl" {
+		enc := json.NewEncoder(os.Stdout)
+		var err error
+		pkgs(func(pkg G, e error) bool {
+			if err = e; err == nil {
+				err = enc.Encode(pkg)
+			}
+			return err == nil
+		})
+		return err
+	}
+
+	collected, err := model.Collect(pkgs)
+	if err != nil {
+		return err
+	}
+	if out, err := formatOutput(collected); err != nil {
+		return err
+	} else {
+		fmt.Print(out)
+		return nil
+	}
+}
+
+// Package filters given by the flags of a command
+func packageQuery(cmd *cobra.Command) client.PackageQuery {
+	query := client.PackageQuery{
+		Repository:      repositoryFilter,
+		Name:            nameFilter,
+		SubmissionState: stateFilter,
+		Archived:        archivedFilter,
+	}
+	if flag := cmd.Flags().Lookup("deleted"); flag != nil && flag.Changed {
+		query.Deleted = &deletedFilter
+	}
+	return query
+}
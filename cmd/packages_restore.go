@@ -0,0 +1,56 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	packagesRestoreCmd.Flags().StringVar(&nameFilter, "name", "", "filter by name glob pattern")
+	packagesRestoreCmd.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository to filter with")
+	packagesRestoreCmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "do not restore anything and just show what would be done")
+	packagesCmd.AddCommand(packagesRestoreCmd)
+}
+
+var packagesRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore one or many deleted packages",
+	Long: `Restore one or many packages deleted with 'packages delete --soft'.
+Packages that were deleted without --soft are gone and cannot be restored.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := packageQuery(cmd)
+		deleted := true
+		query.Deleted = &deleted
+		pkgs, err := Client.ListPackages(commandCtx, query)
+		if err != nil {
+			return err
+		}
+
+		for _, pkg := range pkgs {
+			if dryRun {
+				fmt.Printf("would be restored: %s\n", pkg.Summary())
+				continue
+			}
+			if err := Client.RestorePackage(commandCtx, pkg); err != nil {
+				return fmt.Errorf("could not restore package (%s): %w", pkg.Summary(), err)
+			}
+			fmt.Printf("restored %s\n", pkg.Summary())
+		}
+		return nil
+	},
+}
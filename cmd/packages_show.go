@@ -0,0 +1,57 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/client"
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func init() {
+	packagesShowCmd.Flags().StringVar(&packageVersion, "version", "", "version of the package, the newest version by default")
+	packagesShowCmd.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository of the package, needed when it is in several repositories")
+	packagesCmd.AddCommand(packagesShowCmd)
+}
+
+var (
+	packageVersion string
+
+	packagesShowCmd = &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a single package by name",
+		Long: `Show a single package by name with all its details, such as dependencies,
+license and checksum. Deleted packages are not shown, use 'packages get' with
+their id.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			switch Config.Technology {
+			case "r":
+				return printPackage(client.FindGenericPackage[model.RPackage](commandCtx, Client, name, packageVersion, repositoryFilter))
+			case "python":
+				return printPackage(client.FindGenericPackage[model.PythonPackage](commandCtx, Client, name, packageVersion, repositoryFilter))
+			case "all":
+				return printPackage(client.FindGenericPackage[model.Package](commandCtx, Client, name, packageVersion, repositoryFilter))
+			default:
+				return fmt.Errorf("undefined technology %s", Config.Technology)
+			}
+		},
+	}
+)
@@ -16,39 +16,296 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/spf13/cobra"
 
-	"openanalytics.eu/rdepot/cli/client"
+	"openanalytics.eu/rdepot/cli/model"
 )
 
 func init() {
 	packagesSubmitCmd.Flags().StringVarP(&repository, "repo", "r", "", "repository to upload to")
-	packagesSubmitCmd.PersistentFlags().StringVarP(&filePath, "file", "f", "", "R package archive to upload")
+	packagesSubmitCmd.PersistentFlags().StringArrayVarP(&filePaths, "file", "f", nil, "package archive, directory or glob pattern to upload, can be repeated")
 	packagesSubmitCmd.PersistentFlags().BoolVarP(&replace, "replace", "", true, "replace existing package version")
 	packagesSubmitCmd.PersistentFlags().BoolVarP(&strict, "strict", "", true, "convert warnings into errors")
 	packagesSubmitCmd.PersistentFlags().BoolVarP(&generateManual, "generate-manual", "", true, "generate a manual for the submitted package")
+	packagesSubmitCmd.Flags().BoolVar(&wait, "wait", false, "wait until the submission is accepted or rejected")
+	packagesSubmitCmd.Flags().BoolVar(&noValidate, "no-validate", false, "do not validate archives locally before submitting them")
+	packagesSubmitCmd.Flags().IntVarP(&parallel, "parallel", "p", 1, "number of archives to submit concurrently")
 	packagesCmd.AddCommand(packagesSubmitCmd)
 }
 
 var (
 	repository     string
-	filePath       string
+	filePaths      []string
 	strict         bool
 	replace        bool
 	generateManual bool
+	parallel       int
+	noValidate     bool
 
 	packagesSubmitCmd = &cobra.Command{
-		Use:   "submit",
-		Short: "Submit a package",
-		Long:  `Submit a package to RDepot.`,
+		Use:   "submit [archive|directory|glob]...",
+		Short: "Submit one or many packages",
+		Long: `Submit one or many packages to RDepot.
+
+Archives can be given with --file or as arguments. Directories are expanded
+to the package archives they contain and glob patterns to the files they match.
+Archives are validated locally first, see 'rdepot packages validate'.
+With --strict, the default, warnings of the local validation stop an archive
+from being uploaded, and warnings of the server fail the submission although
+it was already created.
+The exit code is 0 when all submissions succeed, 2 when only some of them
+fail and 1 when all of them fail.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			msg, err := client.SubmitPackage(client.DefaultClient(), Config, filePath, repository, replace, generateManual)
+			if parallel < 1 {
+				return fmt.Errorf("parallel must be at least 1")
+			}
+
+			archives, err := expandArchives(append(filePaths, args...), Config.Technology)
 			if err != nil {
 				return err
 			}
+			if len(archives) == 0 {
+				return fmt.Errorf("no package archives to submit")
+			}
+
+			results := make([]submitResult, len(archives))
+			sem := make(chan struct{}, parallel)
+			var wg sync.WaitGroup
+			for i, archive := range archives {
+				wg.Add(1)
+				sem <- struct{}{}
+				go func(i int, archive string) {
+					defer wg.Done()
+					results[i] = submitArchive(archive)
+					<-sem
+				}(i, archive)
+			}
+			wg.Wait()
+
+			failed := 0
+			var lastErr error
+			for _, res := range results {
+				if res.err != nil {
+					failed++
+					lastErr = res.err
+				}
+			}
 
-			fmt.Println(fmt.Sprintf("Package %s: %s", filePath, msg))
-			return nil
+			if out, err := formatOutput(results); err != nil {
+				return err
+			} else {
+				fmt.Print(out)
+			}
+
+			switch {
+			case failed == 0:
+				return nil
+			case len(results) == 1:
+				return lastErr
+			case failed == len(results):
+				return &ExitError{Code: ExitFailure, Err: fmt.Errorf("all %d submissions failed", failed)}
+			default:
+				return &ExitError{Code: ExitPartialFailure, Err: fmt.Errorf("%d of %d submissions failed", failed, len(results))}
+			}
 		},
 	}
 )
+
+type submitWarning struct {
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+type submitResult struct {
+	File       string            `json:"file"`
+	Message    string            `json:"message,omitempty"`
+	Submission *model.Submission `json:"submission,omitempty"`
+	Warnings   []submitWarning   `json:"warnings,omitempty"`
+	Error      string            `json:"error,omitempty"`
+
+	err error
+}
+
+func (r *submitResult) warn(source string, messages []string) {
+	for _, msg := range messages {
+		r.Warnings = append(r.Warnings, submitWarning{Source: source, Message: msg})
+		fmt.Fprintf(os.Stderr, "Package %s: warning: %s\n", r.File, msg)
+	}
+}
+
+// With --strict any warning collected so far fails the submission
+func (r *submitResult) strictErr() error {
+	if !strict || len(r.Warnings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d warning(s) treated as errors because of --strict", len(r.Warnings))
+}
+
+func (r submitResult) Header(wide bool) []string {
+	header := []string{"FILE", "SUBMISSION", "STATE", "WARNINGS", "RESULT"}
+	if wide {
+		header = append(header, "PACKAGE", "REPOSITORY")
+	}
+	return header
+}
+
+func (r submitResult) Row(wide bool) []string {
+	var id, state, pkg, repo string
+	if r.Submission != nil {
+		id = strconv.Itoa(r.Submission.Id)
+		state = r.Submission.State
+		if r.Submission.Package != nil {
+			pkg = r.Submission.Package.Summary()
+			repo = r.Submission.Package.Repository.Name
+		}
+	}
+	result := r.Message
+	if r.Error != "" {
+		result = r.Error
+	}
+	row := []string{r.File, id, state, strconv.Itoa(len(r.Warnings)), result}
+	if wide {
+		row = append(row, pkg, repo)
+	}
+	return row
+}
+
+func submitArchive(archive string) submitResult {
+	result := submitResult{File: archive}
+	fail := func(err error) submitResult {
+		result.err = err
+		result.Error = err.Error()
+		fmt.Fprintf(os.Stderr, "Package %s: %s\n", archive, err)
+		return result
+	}
+
+	if !noValidate {
+		validated, err := validateArchive(archive)
+		if err != nil {
+			return fail(err)
+		}
+		result.warn("local", validated.Warnings)
+		if !validated.Valid() {
+			return fail(validated.Err())
+		}
+		if err := result.strictErr(); err != nil {
+			return fail(err)
+		}
+	}
+
+	res, err := Client.SubmitPackage(commandCtx, archive, repository, replace, generateManual)
+	if err != nil {
+		return fail(err)
+	}
+	result.Message = res.Message
+	if res.Data.Id != 0 {
+		result.Submission = &res.Data
+	}
+	fmt.Fprintf(os.Stderr, "Package %s: %s\n", archive, res.Message)
+	result.warn("server", res.Warnings())
+	if err := result.strictErr(); err != nil {
+		if result.Submission != nil {
+			return fail(fmt.Errorf("%w, but %s was already created", err, result.Submission.Summary()))
+		}
+		return fail(fmt.Errorf("%w, but the package was already submitted", err))
+	}
+
+	if !wait {
+		return result
+	}
+	if res.Data.Id == 0 {
+		return fail(fmt.Errorf("server did not return the created submission"))
+	}
+
+	ctx, cancel := waitContext()
+	defer cancel()
+	submission, err := Client.WaitForSubmission(ctx, res.Data.Id, pollInterval)
+	if err != nil {
+		return fail(err)
+	}
+	result.Submission = &submission
+
+	switch submission.State {
+	case model.SubmissionAccepted:
+		fmt.Fprintf(os.Stderr, "Package %s: %s\n", archive, strings.ToLower(submission.State))
+		return result
+	case model.SubmissionRejected:
+		if submission.RejectReason != "" {
+			return fail(fmt.Errorf("%s was rejected: %s", submission.Summary(), submission.RejectReason))
+		}
+		return fail(fmt.Errorf("%s was rejected", submission.Summary()))
+	default:
+		return fail(fmt.Errorf("%s was %s", submission.Summary(), strings.ToLower(submission.State)))
+	}
+}
+
+// Extensions of the package archives accepted for a technology
+func archiveExtensions(technology string) []string {
+	switch technology {
+	case "python":
+		return []string{".tar.gz", ".whl", ".zip"}
+	default:
+		return []string{".tar.gz"}
+	}
+}
+
+// Expand directories and glob patterns into the package archives they contain
+func expandArchives(inputs []string, technology string) ([]string, error) {
+	var archives []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			archives = append(archives, path)
+		}
+	}
+	isArchive := func(name string) bool {
+		for _, ext := range archiveExtensions(technology) {
+			if strings.HasSuffix(name, ext) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, input := range inputs {
+		paths := []string{input}
+		if strings.ContainsAny(input, "*?[") {
+			matches, err := filepath.Glob(input)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", input)
+			}
+			paths = matches
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(path)
+				continue
+			}
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if !entry.IsDir() && isArchive(entry.Name()) {
+					add(filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+	}
+	return archives, nil
+}
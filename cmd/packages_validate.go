@@ -0,0 +1,85 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/validation"
+)
+
+func init() {
+	packagesCmd.AddCommand(packagesValidateCmd)
+}
+
+var packagesValidateCmd = &cobra.Command{
+	Use:   "validate [archive|directory|glob]...",
+	Short: "Validate package archives locally",
+	Long: `Validate package archives locally before submitting them to RDepot.
+
+For R source packages the DESCRIPTION file is checked for mandatory fields
+and a valid version, the archive must be named Package_Version.tar.gz and
+contain exactly one top-level directory.
+
+For Python distributions PKG-INFO (source distributions) or METADATA (wheels)
+is read, its Name and Version must match the archive name and the version must
+be valid according to PEP 440.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archives, err := expandArchives(args, Config.Technology)
+		if err != nil {
+			return err
+		}
+
+		results := make([]validation.Result, 0, len(archives))
+		invalid := 0
+		for _, archive := range archives {
+			res, err := validateArchive(archive)
+			if err != nil {
+				return err
+			}
+			if !res.Valid() {
+				invalid++
+			}
+			results = append(results, res)
+		}
+
+		if out, err := formatOutput(results); err != nil {
+			return err
+		} else {
+			fmt.Print(out)
+		}
+
+		if invalid > 0 {
+			return fmt.Errorf("%d of %d archives are invalid", invalid, len(results))
+		}
+		return nil
+	},
+}
+
+func validateArchive(archive string) (validation.Result, error) {
+	var res validation.Result
+	switch Config.Technology {
+	case "r":
+		res = validation.RPackage(archive)
+	case "python":
+		res = validation.PythonPackage(archive)
+	default:
+		return res, fmt.Errorf("validation is not supported for technology %s", Config.Technology)
+	}
+	return res, nil
+}
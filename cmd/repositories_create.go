@@ -0,0 +1,63 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func init() {
+	repositoriesCreateCmd.Flags().StringVar(&publicationUri, "publication-uri", "", "URI at which the repository will be published")
+	repositoriesCreateCmd.Flags().StringVar(&serverAddress, "server-address", "", "address of the repository server")
+	repositoriesCreateCmd.Flags().StringVar(&hashMethod, "hash-method", "", "hash method used by a Python repository")
+	repositoriesCreateCmd.MarkFlagRequired("publication-uri")
+	repositoriesCreateCmd.MarkFlagRequired("server-address")
+	repositoriesCmd.AddCommand(repositoriesCreateCmd)
+}
+
+var (
+	publicationUri string
+	serverAddress  string
+	hashMethod     string
+
+	repositoriesCreateCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a repository",
+		Long:  `Create a repository for the selected technology ('r' or 'python')`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := Client.CreateRepository(commandCtx, model.Repository{
+				Name:           args[0],
+				PublicationUri: publicationUri,
+				ServerAddress:  serverAddress,
+				HashMethod:     hashMethod,
+			})
+			if err != nil {
+				return err
+			}
+
+			if out, err := formatOutput(repo); err != nil {
+				return err
+			} else {
+				fmt.Print(out)
+				return nil
+			}
+		},
+	}
+)
@@ -0,0 +1,45 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	repositoriesCmd.AddCommand(repositoriesGetCmd)
+}
+
+var repositoriesGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Show a single repository",
+	Long:  `Show a single repository`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, err := Client.GetRepository(commandCtx, args[0])
+		if err != nil {
+			return err
+		}
+
+		if out, err := formatOutput(repo); err != nil {
+			return err
+		} else {
+			fmt.Print(out)
+			return nil
+		}
+	},
+}
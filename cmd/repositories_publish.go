@@ -0,0 +1,108 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/client"
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{repositoriesPublishCmd, repositoriesUnpublishCmd} {
+		cmd.Flags().BoolVar(&wait, "wait", false, "wait until the publication URI reflects the change")
+		repositoriesCmd.AddCommand(cmd)
+	}
+}
+
+const (
+	pollInterval = 2 * time.Second
+	// Limit on waiting when --timeout does not set one for the whole command
+	waitTimeout = 5 * time.Minute
+)
+
+var (
+	wait bool
+
+	repositoriesPublishCmd = &cobra.Command{
+		Use:   "publish <name>",
+		Short: "Publish a repository",
+		Long:  `Publish a repository`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPublished(args[0], true)
+		},
+	}
+
+	repositoriesUnpublishCmd = &cobra.Command{
+		Use:   "unpublish <name>",
+		Short: "Unpublish a repository",
+		Long:  `Unpublish a repository`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setPublished(args[0], false)
+		},
+	}
+)
+
+func setPublished(name string, published bool) error {
+	action := "published"
+	if !published {
+		action = "unpublished"
+	}
+
+	repo, err := Client.GetRepository(commandCtx, name)
+	if err != nil {
+		return err
+	}
+	if repo.Published == published {
+		fmt.Printf("already %s: %s\n", action, repo.Summary())
+		return nil
+	}
+
+	var before client.IndexSnapshot
+	if wait {
+		// a failure to fetch the index only means nothing is served yet
+		before, _ = Client.GetRepositoryIndex(commandCtx, repo)
+	}
+
+	if _, err := Client.PublishRepository(commandCtx, repo, published); err != nil {
+		return fmt.Errorf("could not update repository (%s): %w", repo.Summary(), err)
+	}
+
+	if wait {
+		ctx, cancel := waitContext()
+		defer cancel()
+		if err := Client.WaitForPublication(ctx, repo, before, published, pollInterval); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%s %s\n", action, repo.Summary())
+	return nil
+}
+
+// Context for waiting on the server, limited to waitTimeout unless the
+// command already has a deadline
+func waitContext() (context.Context, context.CancelFunc) {
+	if _, ok := commandCtx.Deadline(); ok {
+		return context.WithCancel(commandCtx)
+	}
+	return context.WithTimeout(commandCtx, waitTimeout)
+}
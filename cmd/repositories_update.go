@@ -0,0 +1,83 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/client"
+)
+
+func init() {
+	repositoriesUpdateCmd.Flags().StringVar(&newName, "new-name", "", "rename the repository")
+	repositoriesUpdateCmd.Flags().StringVar(&publicationUri, "publication-uri", "", "URI at which the repository will be published")
+	repositoriesUpdateCmd.Flags().StringVar(&serverAddress, "server-address", "", "address of the repository server")
+	repositoriesUpdateCmd.Flags().StringVar(&hashMethod, "hash-method", "", "hash method used by a Python repository")
+	repositoriesCmd.AddCommand(repositoriesUpdateCmd)
+}
+
+// Flags of the update command and the repository properties they replace,
+// in the order of the patch operations
+var repositoryUpdates = []struct {
+	flag string
+	path string
+}{
+	{"new-name", "/name"},
+	{"publication-uri", "/publicationUri"},
+	{"server-address", "/serverAddress"},
+	{"hash-method", "/hashMethod"},
+}
+
+var (
+	newName string
+
+	repositoriesUpdateCmd = &cobra.Command{
+		Use:   "update <name>",
+		Short: "Update the properties of a repository",
+		Long:  `Update the properties of a repository, only the given flags are changed`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var patch []client.PatchOperation
+			for _, update := range repositoryUpdates {
+				if cmd.Flags().Changed(update.flag) {
+					value, _ := cmd.Flags().GetString(update.flag)
+					patch = append(patch, client.PatchOperation{Op: "replace", Path: update.path, Value: value})
+				}
+			}
+			if len(patch) == 0 {
+				return fmt.Errorf("nothing to update")
+			}
+
+			repo, err := Client.GetRepository(commandCtx, args[0])
+			if err != nil {
+				return err
+			}
+
+			repo, err = Client.PatchRepository(commandCtx, repo, patch)
+			if err != nil {
+				return err
+			}
+
+			if out, err := formatOutput(repo); err != nil {
+				return err
+			} else {
+				fmt.Print(out)
+				return nil
+			}
+		},
+	}
+)
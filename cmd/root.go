@@ -15,7 +15,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -31,14 +39,59 @@ var (
 		Long: `RDepot is a solution of R package repository management.
 
   More information is available at http://rdepot.io
-  Open Analytics 2020`,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+  Open Analytics 2020
+
+JSONPath output:
+  -o jsonpath=<template> supports the kubectl JSONPath syntax without filters:
+  {.field} or {['field']}, {[n]} with negative n counting from the end, all
+  elements with {[*]} or {.*}, paths from the root with {$...}, text such as
+  {"\n"} and {range <path>}...{end}. Missing fields and indexes print nothing.
+
+Exit codes:
+  0  success
+  1  failure
+  2  partial failure, some of the requested actions failed
+  3  not authorized
+  4  resource not found
+  5  conflict with an existing resource
+  6  invalid request
+  130  interrupted`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkOutput(); err != nil {
+				return err
+			}
+			if err := applyConfigFile(); err != nil {
+				return err
+			}
+			commandCtx = cmd.Context()
+			if commandCtx == nil {
+				commandCtx = context.Background()
+			}
+			if timeout > 0 {
+				commandCtx, cancelCommand = context.WithTimeout(commandCtx, timeout)
+			}
+
 			Config = client.RDepotConfig{
-				Host:       viper.GetString("host"),
-				Token:      viper.GetString("token"),
-				Username:   viper.GetString("username"),
-				Technology: viper.GetString("technology"),
+				Host:        viper.GetString("host"),
+				Token:       viper.GetString("token"),
+				Username:    viper.GetString("username"),
+				Technology:  viper.GetString("technology"),
+				Credentials: storedCredentials,
+			}
+			if viper.GetBool("insecure-skip-verify") {
+				fmt.Fprintf(os.Stderr, "WARNING: TLS certificate verification is disabled with insecure-skip-verify, the connection to %s is not secure\n", Config.Host)
 			}
+			opts, err := clientOptions()
+			if err != nil {
+				return err
+			}
+			if auth, err := authenticator(); err != nil {
+				return err
+			} else if auth != nil {
+				opts = append(opts, client.WithAuthenticator(auth))
+			}
+			Client = client.NewFromConfig(Config, opts...)
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return nil
@@ -50,8 +103,15 @@ var (
 	Username   string
 	Technology TechnologyEnum = TechnologyEnum("r")
 	output                    = "json"
+	verbose    bool
+	timeout    time.Duration
 
 	Config client.RDepotConfig
+	Client *client.Client
+
+	// Context of the running command, done on interrupt or when --timeout expires
+	commandCtx                       = context.Background()
+	cancelCommand context.CancelFunc = func() {}
 )
 
 func init() {
@@ -59,34 +119,157 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&Token, "token", "", "", "API token expects 'username:token' when the username flag is not used and 'token' otherwise")
 	rootCmd.PersistentFlags().StringVarP(&Username, "username", "", "", "Username to be used as the first part of the token")
 	rootCmd.PersistentFlags().VarP(&Technology, "technology", "", "Technology that will be used. Values can be 'r', 'python' or 'all'.")
+	rootCmd.PersistentFlags().String("auth", "basic", "authentication: 'basic' with the token and username, 'bearer' with the token as bearer token or 'oidc' with tokens of the identity provider from 'rdepot login'")
+	rootCmd.PersistentFlags().String("oidc-issuer", "", "issuer URL of the OpenID Connect provider, e.g. https://sso.example.com/realms/rdepot")
+	rootCmd.PersistentFlags().String("oidc-client-id", "rdepot-cli", "client ID registered with the OpenID Connect provider")
+	rootCmd.PersistentFlags().String("oidc-scopes", "openid offline_access", "space separated scopes requested from the OpenID Connect provider")
+	rootCmd.PersistentFlags().String("ca-cert", "", "PEM file with certificates of authorities to trust in addition to the ones of the system")
+	rootCmd.PersistentFlags().String("client-cert", "", "PEM file with the client certificate for mutual TLS")
+	rootCmd.PersistentFlags().String("client-key", "", "PEM file with the private key of the client certificate, if not in the certificate file")
+	rootCmd.PersistentFlags().Bool("insecure-skip-verify", false, "do not verify the certificate of the host, INSECURE: only for testing")
+	rootCmd.PersistentFlags().String("config", "", "configuration file, by default config.yaml in the rdepot directory of the user configuration directory")
+	rootCmd.PersistentFlags().String("context", "", "context of the configuration file to use instead of the current context")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", output, "output format: "+outputFormats)
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "log requests to the RDepot API")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "maximum duration of the command including waiting, 0 means no limit")
+	rootCmd.PersistentFlags().Int("retries", client.DefaultMaxRetries, "number of times a request failing with a transient error is retried")
+	rootCmd.PersistentFlags().Bool("retry-non-idempotent", false, "also retry requests that are not idempotent, such as submissions")
+	rootCmd.PersistentFlags().Int("page-size", client.DefaultPageSize, "number of items requested per page when listing")
+	rootCmd.PersistentFlags().Int("page-concurrency", client.DefaultPageConcurrency, "number of pages fetched concurrently when listing")
 	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
 	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
 	viper.BindPFlag("username", rootCmd.PersistentFlags().Lookup("username"))
 	viper.BindPFlag("technology", rootCmd.PersistentFlags().Lookup("technology"))
+	viper.BindPFlag("retries", rootCmd.PersistentFlags().Lookup("retries"))
+	viper.BindPFlag("retry-non-idempotent", rootCmd.PersistentFlags().Lookup("retry-non-idempotent"))
+	viper.BindPFlag("page-size", rootCmd.PersistentFlags().Lookup("page-size"))
+	viper.BindPFlag("page-concurrency", rootCmd.PersistentFlags().Lookup("page-concurrency"))
+	viper.BindPFlag("auth", rootCmd.PersistentFlags().Lookup("auth"))
+	viper.BindPFlag("oidc-issuer", rootCmd.PersistentFlags().Lookup("oidc-issuer"))
+	viper.BindPFlag("oidc-client-id", rootCmd.PersistentFlags().Lookup("oidc-client-id"))
+	viper.BindPFlag("oidc-scopes", rootCmd.PersistentFlags().Lookup("oidc-scopes"))
+	viper.BindPFlag("ca-cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	viper.BindPFlag("client-cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	viper.BindPFlag("client-key", rootCmd.PersistentFlags().Lookup("client-key"))
+	viper.BindPFlag("insecure-skip-verify", rootCmd.PersistentFlags().Lookup("insecure-skip-verify"))
+	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+	viper.BindPFlag("context", rootCmd.PersistentFlags().Lookup("context"))
 	viper.SetEnvPrefix("RDEPOT")
 	viper.BindEnv("token")
 	viper.BindEnv("host")
 	viper.BindEnv("username")
 	viper.BindEnv("technology")
+	viper.BindEnv("auth")
+	viper.BindEnv("oidc-issuer", "RDEPOT_OIDC_ISSUER")
+	viper.BindEnv("oidc-client-id", "RDEPOT_OIDC_CLIENT_ID")
+	viper.BindEnv("oidc-scopes", "RDEPOT_OIDC_SCOPES")
+	viper.BindEnv("ca-cert", "RDEPOT_CA_CERT")
+	viper.BindEnv("client-cert", "RDEPOT_CLIENT_CERT")
+	viper.BindEnv("client-key", "RDEPOT_CLIENT_KEY")
+	viper.BindEnv("insecure-skip-verify", "RDEPOT_INSECURE_SKIP_VERIFY")
+	viper.BindEnv("config")
+	viper.BindEnv("context")
+	viper.BindEnv("retries")
+	viper.BindEnv("retry-non-idempotent", "RDEPOT_RETRY_NON_IDEMPOTENT")
+	viper.BindEnv("page-size", "RDEPOT_PAGE_SIZE")
+	viper.BindEnv("page-concurrency", "RDEPOT_PAGE_CONCURRENCY")
 }
 
+// HTTP client for the global flags
+func httpClient() (*http.Client, error) {
+	base, err := client.NewTransport(client.TLSOptions{
+		CACert:             viper.GetString("ca-cert"),
+		ClientCert:         viper.GetString("client-cert"),
+		ClientKey:          viper.GetString("client-key"),
+		InsecureSkipVerify: viper.GetBool("insecure-skip-verify"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	transport := client.NewRetryTransport(base)
+	transport.MaxRetries = viper.GetInt("retries")
+	transport.RetryNonIdempotent = viper.GetBool("retry-non-idempotent")
+	if verbose {
+		transport.Logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// Options of the client for the global flags
+func clientOptions() ([]client.Option, error) {
+	httpClient, err := httpClient()
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.Option{
+		client.WithUserAgent("rdepot-cli/" + version),
+		client.WithHTTPClient(httpClient),
+		client.WithPageSize(viper.GetInt("page-size")),
+		client.WithPageConcurrency(viper.GetInt("page-concurrency")),
+	}
+	if verbose {
+		opts = append(opts, client.WithLogger(log.New(os.Stderr, "", log.LstdFlags)))
+	}
+	return opts, nil
+}
+
+// Run the command line, an interrupt or termination signal cancels the
+// requests in flight
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	cancelCommand()
+	if err != nil && ctx.Err() != nil {
+		return &ExitError{Code: ExitInterrupted, Err: err}
+	}
+	return err
 }
 
 type ByteArray []byte
 
+const outputFormats = "json, jsonl, yaml, table, wide, csv, go-template=<template> or jsonpath=<template>"
+
+// Check the output format before a command does any work
+func checkOutput() error {
+	switch {
+	case output == "json", output == "jsonl", output == "yaml", output == "table", output == "wide", output == "csv":
+		return nil
+	case strings.HasPrefix(output, "go-template="), strings.HasPrefix(output, "jsonpath="):
+		return nil
+	default:
+		return fmt.Errorf("output format not supported: %s, use %s", output, outputFormats)
+	}
+}
+
 func formatOutput(out model.Output) (string, error) {
-	switch output {
-	case "json":
-		if res, err := model.FormatJSON(out); err != nil {
-			return "", err
-		} else {
-			return string(res), nil
+	var res []byte
+	var err error
+	switch {
+	case output == "json":
+		if res, err = model.FormatJSON(out); err == nil {
+			res = append(res, '\n')
 		}
+	case output == "jsonl":
+		res, err = model.FormatJSONLines(out)
+	case output == "yaml":
+		res, err = model.FormatYAML(out)
+	case output == "table", output == "wide":
+		res, err = model.FormatTable(out, output == "wide")
+	case output == "csv":
+		res, err = model.FormatCSV(out, false)
+	case strings.HasPrefix(output, "go-template="):
+		res, err = model.FormatTemplate(out, strings.TrimPrefix(output, "go-template="))
+	case strings.HasPrefix(output, "jsonpath="):
+		res, err = model.FormatJSONPath(out, strings.TrimPrefix(output, "jsonpath="))
 	default:
-		return "", fmt.Errorf("error type not supported: %s", output)
+		return "", checkOutput()
+	}
+	if err != nil {
+		return "", err
 	}
+	return string(res), nil
 }
 
 func (o ByteArray) FormatJSON() ([]byte, error) {
@@ -0,0 +1,51 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	submissionsCmd.AddCommand(submissionsGetCmd)
+}
+
+var submissionsGetCmd = &cobra.Command{
+	Use:   "get <id>",
+	Short: "Show a single submission",
+	Long:  `Show a single submission`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid submission id %s", args[0])
+		}
+
+		submission, err := Client.GetSubmission(commandCtx, id)
+		if err != nil {
+			return err
+		}
+
+		if out, err := formatOutput(submission); err != nil {
+			return err
+		} else {
+			fmt.Print(out)
+			return nil
+		}
+	},
+}
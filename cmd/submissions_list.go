@@ -0,0 +1,68 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func init() {
+	submissionsListCmd.Flags().StringVar(&stateFilter, "state", "", "filter by state (waiting, accepted, rejected or cancelled)")
+	submissionsListCmd.Flags().StringVarP(&repositoryFilter, "repo", "r", "", "repository to filter with")
+	submissionsListCmd.Flags().StringVar(&submitterFilter, "submitter", "", "filter by login of the submitter")
+	submissionsCmd.AddCommand(submissionsListCmd)
+}
+
+var (
+	stateFilter     string
+	submitterFilter string
+
+	submissionsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List one or many submissions",
+		Long:  `List one or many submissions`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkState(stateFilter); err != nil {
+				return err
+			}
+
+			submissions, err := Client.ListSubmissions(commandCtx, stateFilter, repositoryFilter, submitterFilter)
+			if err != nil {
+				return err
+			}
+
+			if out, err := formatOutput(submissions); err != nil {
+				return err
+			} else {
+				fmt.Print(out)
+				return nil
+			}
+		},
+	}
+)
+
+func checkState(state string) error {
+	switch strings.ToUpper(state) {
+	case "", model.SubmissionWaiting, model.SubmissionAccepted, model.SubmissionRejected, model.SubmissionCancelled:
+		return nil
+	default:
+		return fmt.Errorf("undefined submission state %s", state)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+func init() {
+	submissionsRejectCmd.Flags().StringVar(&rejectReason, "reason", "", "reason for rejecting the submission")
+	submissionsCmd.AddCommand(submissionsAcceptCmd)
+	submissionsCmd.AddCommand(submissionsRejectCmd)
+	submissionsCmd.AddCommand(submissionsCancelCmd)
+}
+
+var (
+	rejectReason string
+
+	submissionsAcceptCmd = &cobra.Command{
+		Use:   "accept <id>...",
+		Short: "Accept one or many waiting submissions",
+		Long:  `Accept one or many waiting submissions`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewSubmissions(args, "accepted", func(s model.Submission) (model.Submission, error) {
+				return Client.AcceptSubmission(commandCtx, s)
+			})
+		},
+	}
+
+	submissionsRejectCmd = &cobra.Command{
+		Use:   "reject <id>...",
+		Short: "Reject one or many waiting submissions",
+		Long:  `Reject one or many waiting submissions`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewSubmissions(args, "rejected", func(s model.Submission) (model.Submission, error) {
+				return Client.RejectSubmission(commandCtx, s, rejectReason)
+			})
+		},
+	}
+
+	submissionsCancelCmd = &cobra.Command{
+		Use:   "cancel <id>...",
+		Short: "Cancel one or many of your own waiting submissions",
+		Long:  `Cancel one or many of your own waiting submissions`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewSubmissions(args, "cancelled", func(s model.Submission) (model.Submission, error) {
+				return Client.CancelSubmission(commandCtx, s)
+			})
+		},
+	}
+)
+
+func reviewSubmissions(args []string, action string, review func(model.Submission) (model.Submission, error)) error {
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid submission id %s", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	for _, id := range ids {
+		submission, err := Client.GetSubmission(commandCtx, id)
+		if err != nil {
+			return fmt.Errorf("could not get submission %d: %w", id, err)
+		}
+		if submission.State != model.SubmissionWaiting {
+			return fmt.Errorf("%s is not waiting but %s", submission.Summary(), submission.State)
+		}
+		if _, err := review(submission); err != nil {
+			return fmt.Errorf("could not update %s: %w", submission.Summary(), err)
+		}
+		fmt.Printf("%s %s\n", action, submission.Summary())
+	}
+	return nil
+}
@@ -24,11 +24,13 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 }
 
+const version = "2.0.0"
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of rdepot-cli",
 	Long:  `The version of rdepot-cli is increased in tandem with RDepot`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("rdepot-cli v2.0.0")
+		fmt.Println("rdepot-cli v" + version)
 	},
 }
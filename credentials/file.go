@@ -0,0 +1,229 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// A store in a file encrypted with AES-GCM, using a key derived from a
+// passphrase with scrypt. Passphrase is called every time the file is read
+// or written, so a prompt should remember the answer.
+type FileStore struct {
+	Path       string
+	Passphrase func() (string, error)
+}
+
+// Layout of the file, the byte fields are base64 encoded by encoding/json.
+// The keys of the credentials are kept in plain text, authenticated with the
+// data, so that Holds needs no passphrase.
+type encryptedFile struct {
+	Version int      `json:"version"`
+	Keys    []string `json:"keys,omitempty"`
+	Salt    []byte   `json:"salt"`
+	Nonce   []byte   `json:"nonce"`
+	Data    []byte   `json:"data"`
+}
+
+// Additional data of the encryption, none for files without keys
+func (f encryptedFile) additionalData() ([]byte, error) {
+	if len(f.Keys) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(f.Keys)
+}
+
+func (s FileStore) Get(host string) (Credential, error) {
+	creds, err := s.read()
+	if err != nil {
+		return Credential{}, err
+	}
+	cred, ok := creds[hostKey(host)]
+	if !ok {
+		return Credential{}, ErrNotFound
+	}
+	return cred, nil
+}
+
+func (s FileStore) Set(host string, cred Credential) error {
+	creds, err := s.read()
+	if err != nil {
+		return err
+	}
+	creds[hostKey(host)] = cred
+	return s.write(creds)
+}
+
+func (s FileStore) Delete(host string) error {
+	if _, err := os.Stat(s.Path); errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	creds, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := creds[hostKey(host)]; !ok {
+		return ErrNotFound
+	}
+	delete(creds, hostKey(host))
+	return s.write(creds)
+}
+
+func (s FileStore) Holds(host string) (bool, error) {
+	file, err := s.readFile()
+	if err != nil || file == nil {
+		return false, err
+	}
+	for _, key := range file.Keys {
+		if key == hostKey(host) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+func (s FileStore) passphrase() (string, error) {
+	if s.Passphrase == nil {
+		return "", fmt.Errorf("no passphrase for the credentials file %s", s.Path)
+	}
+	return s.Passphrase()
+}
+
+// Read the file without decrypting it, nil when it does not exist
+func (s FileStore) readFile() (*encryptedFile, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %w", s.Path, err)
+	}
+	if file.Version != 1 {
+		return nil, fmt.Errorf("unsupported version %d of credentials file %s", file.Version, s.Path)
+	}
+	return &file, nil
+}
+
+// Decrypt the credentials, a missing file holds none
+func (s FileStore) read() (map[string]Credential, error) {
+	creds := map[string]Credential{}
+
+	file, err := s.readFile()
+	if err != nil {
+		return nil, err
+	} else if file == nil {
+		return creds, nil
+	}
+
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	additionalData, err := file.additionalData()
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt credentials file %s, wrong passphrase?", s.Path)
+	}
+
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// Encrypt the credentials with a fresh salt and nonce
+func (s FileStore) write(creds map[string]Credential) error {
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return err
+	}
+	file := encryptedFile{Version: 1, Salt: make([]byte, 16)}
+	for key := range creds {
+		file.Keys = append(file.Keys, key)
+	}
+	sort.Strings(file.Keys)
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	key, err := deriveKey(passphrase, file.Salt)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	additionalData, err := file.additionalData()
+	if err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, additionalData)
+
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, data, 0600)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
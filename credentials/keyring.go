@@ -0,0 +1,62 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// A store in the keyring of the operating system: the Keychain on macOS,
+// the Credential Manager on Windows and the Secret Service on Linux
+type KeyringStore struct {
+	Service string
+}
+
+func NewKeyringStore() KeyringStore {
+	return KeyringStore{Service: "rdepot-cli"}
+}
+
+func (s KeyringStore) Get(host string) (Credential, error) {
+	var cred Credential
+
+	secret, err := keyring.Get(s.Service, hostKey(host))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return cred, ErrNotFound
+	} else if err != nil {
+		return cred, err
+	}
+
+	err = json.Unmarshal([]byte(secret), &cred)
+	return cred, err
+}
+
+func (s KeyringStore) Set(host string, cred Credential) error {
+	secret, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(s.Service, hostKey(host), string(secret))
+}
+
+func (s KeyringStore) Delete(host string) error {
+	err := keyring.Delete(s.Service, hostKey(host))
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentials stores RDepot tokens outside of flags, environment
+// variables and configuration files: in the keyring of the operating system
+// or, where there is none, in a file encrypted with a passphrase.
+package credentials
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("credentials not found")
+
+// Username and token to authenticate with an RDepot server. With OIDC the
+// token is an access token, stored with its refresh token and expiry.
+type Credential struct {
+	Username     string    `json:"username,omitempty"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// Credentials keyed by RDepot host
+type Store interface {
+	// Returns ErrNotFound when no credential is stored for host
+	Get(host string) (Credential, error)
+	Set(host string, cred Credential) error
+	// Returns ErrNotFound when no credential is stored for host
+	Delete(host string) error
+}
+
+// Implemented by stores that can tell whether they hold a credential for a
+// host without being unlocked, e.g. without asking for a passphrase
+type Holder interface {
+	Holds(host string) (bool, error)
+}
+
+// Key of the credentials of a host, so that https://rdepot.example.com/ and
+// https://rdepot.example.com share them
+func hostKey(host string) string {
+	return strings.TrimRight(host, "/")
+}
+
+// A store that uses Primary, typically the keyring, and Fallback when
+// Primary is not available
+type FallbackStore struct {
+	Primary  Store
+	Fallback Store
+}
+
+func (s FallbackStore) Get(host string) (Credential, error) {
+	if cred, err := s.Primary.Get(host); err == nil {
+		return cred, nil
+	}
+	// credentials may have been stored while the primary store was not
+	// available, only unlock the fallback when it holds them
+	if holder, ok := s.Fallback.(Holder); ok {
+		if held, err := holder.Holds(host); err == nil && !held {
+			return Credential{}, ErrNotFound
+		}
+	}
+	return s.Fallback.Get(host)
+}
+
+func (s FallbackStore) Set(host string, cred Credential) error {
+	if err := s.Primary.Set(host, cred); err == nil {
+		// do not leave an outdated credential behind in the fallback, when
+		// that does not mean unlocking it
+		if holder, ok := s.Fallback.(Holder); ok {
+			if held, err := holder.Holds(host); err == nil && held {
+				return s.Fallback.Delete(host)
+			}
+		}
+		return nil
+	}
+	return s.Fallback.Set(host, cred)
+}
+
+func (s FallbackStore) Delete(host string) error {
+	primaryErr := s.Primary.Delete(host)
+	var fallbackErr error
+	if holder, ok := s.Fallback.(Holder); ok {
+		if held, err := holder.Holds(host); err == nil && !held {
+			fallbackErr = ErrNotFound
+		}
+	}
+	if fallbackErr == nil {
+		fallbackErr = s.Fallback.Delete(host)
+	}
+	switch {
+	case primaryErr == nil || fallbackErr == nil:
+		return nil
+	case errors.Is(fallbackErr, ErrNotFound):
+		return ErrNotFound
+	default:
+		return fallbackErr
+	}
+}
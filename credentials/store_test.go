@@ -0,0 +1,147 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credentials
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func passphrase(p string) func() (string, error) {
+	return func() (string, error) {
+		return p, nil
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rdepot", "credentials")
+	store := FileStore{Path: path, Passphrase: passphrase("secret")}
+
+	if _, err := store.Get("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	cred := Credential{Username: "einstein", Token: "validtoken"}
+	if err := store.Set("https://rdepot.example.com/", cred); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, err := store.Get("https://rdepot.example.com"); err != nil || got != cred {
+		t.Errorf("expected %v, got %v (%v)", cred, got, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("validtoken")) {
+		t.Errorf("token stored in plain text")
+	}
+
+	wrong := FileStore{Path: path, Passphrase: passphrase("guess")}
+	if _, err := wrong.Get("https://rdepot.example.com"); err == nil {
+		t.Errorf("expected error with the wrong passphrase")
+	}
+
+	if err := store.Delete("https://rdepot.example.com"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := store.Delete("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// A store that is not available, like a keyring without Secret Service
+type unavailableStore struct{}
+
+var errUnavailable = errors.New("unavailable")
+
+func (unavailableStore) Get(host string) (Credential, error)    { return Credential{}, errUnavailable }
+func (unavailableStore) Set(host string, cred Credential) error { return errUnavailable }
+func (unavailableStore) Delete(host string) error               { return errUnavailable }
+
+func TestFallbackStore(t *testing.T) {
+	keyring.MockInit()
+
+	file := FileStore{Path: filepath.Join(t.TempDir(), "credentials"), Passphrase: passphrase("secret")}
+	cred := Credential{Token: "einstein:validtoken"}
+
+	// without keyring the file is used
+	headless := FallbackStore{Primary: unavailableStore{}, Fallback: file}
+	if _, err := headless.Get("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := headless.Set("https://rdepot.example.com", cred); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, err := file.Get("https://rdepot.example.com"); err != nil || got != cred {
+		t.Errorf("expected %v in the file, got %v (%v)", cred, got, err)
+	}
+
+	// with keyring the credential moves out of the file
+	desktop := FallbackStore{Primary: NewKeyringStore(), Fallback: file}
+	if got, err := desktop.Get("https://rdepot.example.com"); err != nil || got != cred {
+		t.Errorf("expected %v from the file, got %v (%v)", cred, got, err)
+	}
+	if err := desktop.Set("https://rdepot.example.com", cred); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if _, err := file.Get("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected credential to be removed from the file, got %v", err)
+	}
+	if got, err := NewKeyringStore().Get("https://rdepot.example.com"); err != nil || got != cred {
+		t.Errorf("expected %v in the keyring, got %v (%v)", cred, got, err)
+	}
+
+	if err := desktop.Delete("https://rdepot.example.com"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := desktop.Delete("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := headless.Delete("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// a file without the host is not unlocked
+	locked := FileStore{Path: file.Path, Passphrase: func() (string, error) {
+		t.Errorf("unexpected passphrase prompt")
+		return "", errors.New("no passphrase")
+	}}
+	if held, err := locked.Holds("https://rdepot.example.com"); err != nil || held {
+		t.Errorf("expected the file not to hold the host, got %t (%v)", held, err)
+	}
+	desktop = FallbackStore{Primary: NewKeyringStore(), Fallback: locked}
+	if err := desktop.Set("https://rdepot.example.com", cred); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// nor is a file holding only other hosts when looking up credentials
+	if err := file.Set("https://other.example.com", cred); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	headless = FallbackStore{Primary: unavailableStore{}, Fallback: locked}
+	if _, err := headless.Get("https://rdepot.example.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -21,6 +21,6 @@ import (
 
 func main() {
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -0,0 +1,89 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The DESCRIPTION file of an R package
+type Description struct {
+	Package     string            `json:"package"`
+	Version     string            `json:"version"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Author      string            `json:"author"`
+	Maintainer  string            `json:"maintainer"`
+	License     string            `json:"license"`
+	Fields      map[string]string `json:"fields"`
+}
+
+// Parse an R DESCRIPTION file in Debian Control File (DCF) format. Field
+// values spanning multiple lines are joined with newlines.
+func ParseDescription(r io.Reader) (*Description, error) {
+	fields := make(map[string]string)
+	var field string
+	var ended bool
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		switch {
+		case strings.TrimSpace(text) == "":
+			ended = len(fields) > 0
+		case ended:
+			return nil, fmt.Errorf("line %d: DESCRIPTION must contain a single record", line)
+		case text[0] == ' ' || text[0] == '\t':
+			if field == "" {
+				return nil, fmt.Errorf("line %d: continuation line without a field", line)
+			}
+			fields[field] += "\n" + strings.TrimSpace(text)
+		default:
+			key, value, found := strings.Cut(text, ":")
+			if !found || key == "" || strings.ContainsAny(key, " \t") {
+				return nil, fmt.Errorf("line %d: expected 'Field: value', got %q", line, text)
+			}
+			if _, exists := fields[key]; exists {
+				return nil, fmt.Errorf("line %d: duplicate field %s", line, key)
+			}
+			field = key
+			fields[field] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &Description{
+		Package:     fields["Package"],
+		Version:     fields["Version"],
+		Title:       fields["Title"],
+		Description: fields["Description"],
+		Author:      fields["Author"],
+		Maintainer:  fields["Maintainer"],
+		License:     fields["License"],
+		Fields:      fields,
+	}, nil
+}
+
+// Look up the value of any field, return false if it is not present
+func (d Description) Get(field string) (string, bool) {
+	value, ok := d.Fields[field]
+	return value, ok
+}
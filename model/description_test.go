@@ -0,0 +1,60 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDescription(t *testing.T) {
+	desc, err := ParseDescription(strings.NewReader(`Package: oaColors
+Type: Package
+Title: Open Analytics Colors Package
+Version: 0.0.4
+Date: 2015-07-07
+Author: Jason Waddell
+Maintainer: Jason Waddell <jason.waddell@openanalytics.eu>
+Description: Dedicated color palettes
+    and functions.
+License: GPL-3
+Packaged: 2015-07-07 11:06:54 UTC; jason
+
+`))
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	if desc.Package != "oaColors" || desc.Version != "0.0.4" || desc.License != "GPL-3" {
+		t.Errorf("unexpected fields: %s %s %s", desc.Package, desc.Version, desc.License)
+	}
+	if desc.Description != "Dedicated color palettes\nand functions." {
+		t.Errorf("unexpected continuation: %q", desc.Description)
+	}
+	if v, ok := desc.Get("Type"); !ok || v != "Package" {
+		t.Errorf("expected Type field, got %q", v)
+	}
+
+	invalid := []string{
+		"Package oaColors\n",
+		"  continued\nPackage: oaColors\n",
+		"Package: oaColors\nPackage: oaColors\n",
+		"Package: oaColors\n\nVersion: 0.0.4\n",
+	}
+	for _, text := range invalid {
+		if _, err := ParseDescription(strings.NewReader(text)); err == nil {
+			t.Errorf("expected error for %q", text)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A named field of the detailed view of a value
+type Detail struct {
+	Name  string
+	Value string
+}
+
+// A value with a detailed view, shown instead of a table when a single value
+// is printed
+type Detailed interface {
+	Details() []Detail
+}
+
+// Format the details as aligned 'Name: value' lines, lines of multi-line
+// values are aligned with the first one and empty values are left out
+func FormatDetails(d Detailed) []byte {
+	var details []Detail
+	width := 0
+	for _, detail := range d.Details() {
+		detail.Value = strings.TrimSpace(detail.Value)
+		if detail.Value == "" {
+			continue
+		}
+		details = append(details, detail)
+		if len(detail.Name) > width {
+			width = len(detail.Name)
+		}
+	}
+
+	var b bytes.Buffer
+	for _, detail := range details {
+		for i, line := range strings.Split(detail.Value, "\n") {
+			name := ""
+			if i == 0 {
+				name = detail.Name + ":"
+			}
+			fmt.Fprintf(&b, "%-*s   %s\n", width+1, name, strings.TrimSpace(line))
+		}
+	}
+	return b.Bytes()
+}
+
+func (pkg Package) Details() []Detail {
+	return pkg.details()
+}
+
+// Details of the common fields, with the details of a technology before the
+// description and links
+func (pkg Package) details(technology ...Detail) []Detail {
+	details := []Detail{
+		{"ID", strconv.Itoa(pkg.Id)},
+		{"Name", pkg.Name},
+		{"Version", pkg.Version.CanonicalRep},
+		{"Title", pkg.Title},
+		{"Repository", pkg.Repository.Name},
+		{"Technology", pkg.Technology},
+		{"Active", strconv.FormatBool(pkg.Active)},
+		{"Deleted", strconv.FormatBool(pkg.Deleted)},
+		{"Maintainer", pkg.User.Login},
+		{"Author", pkg.Author},
+		{"URL", pkg.Url},
+		{"Source", pkg.Source},
+	}
+	if pkg.Submission.Id != 0 {
+		details = append(details, Detail{"Submission", fmt.Sprintf("%d (%s)", pkg.Submission.Id, pkg.Submission.State)})
+	}
+	details = append(details, technology...)
+
+	links := make([]string, 0, len(pkg.Links))
+	for _, link := range pkg.Links {
+		links = append(links, link.Rel+": "+link.Href)
+	}
+	return append(details,
+		Detail{"Description", pkg.Description},
+		Detail{"Links", strings.Join(links, "\n")},
+	)
+}
+
+func (pkg RPackage) Details() []Detail {
+	return pkg.Package.details(
+		Detail{"License", pkg.License},
+		Detail{"Depends", pkg.Depends},
+		Detail{"Imports", pkg.Imports},
+		Detail{"Suggests", pkg.Suggests},
+		Detail{"System requirements", pkg.SystemRequirements},
+		Detail{"MD5 sum", pkg.Md5sum},
+	)
+}
+
+func (pkg PythonPackage) Details() []Detail {
+	return pkg.Package.details(
+		Detail{"Summary", pkg.SummaryField},
+		Detail{"License", pkg.License},
+		Detail{"Author email", pkg.AuthorEmail},
+		Detail{"Maintainer email", pkg.MaintainerEmail},
+		Detail{"Home page", pkg.HomePage},
+		Detail{"Project URL", pkg.ProjectUrl},
+		Detail{"Keywords", pkg.Keywords},
+		Detail{"Classifiers", pkg.Classifiers},
+		Detail{"Platform", pkg.Platform},
+		Detail{"Requires Python", pkg.RequiresPython},
+		Detail{"Requires", pkg.RequiresDist},
+		Detail{"Requires external", pkg.RequiresExternal},
+		Detail{"Provides extra", pkg.ProvidesExtra},
+		Detail{"Hash", pkg.Hash},
+	)
+}
@@ -0,0 +1,82 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	Wheel = "wheel"
+	Sdist = "sdist"
+)
+
+// The components of a Python distribution filename
+type PythonFilename struct {
+	Kind        string
+	Name        string
+	Version     string
+	BuildTag    string
+	PythonTag   string
+	AbiTag      string
+	PlatformTag string
+}
+
+var (
+	nameSeparators = regexp.MustCompile(`[-_.]+`)
+	wheelFilename  = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._]*[A-Za-z0-9])?)-(\d[^-]*)(?:-(\d[^-]*))?-([^-]+)-([^-]+)-([^-]+)\.whl$`)
+	sdistFilename  = regexp.MustCompile(`^([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)-(\d[^-]*)\.(?:tar\.gz|zip)$`)
+)
+
+// Parse the filename of a wheel or source distribution following the
+// binary distribution format and source distribution format specifications
+func ParsePythonFilename(filename string) (*PythonFilename, error) {
+	if strings.HasSuffix(filename, ".whl") {
+		m := wheelFilename.FindStringSubmatch(filename)
+		if m == nil {
+			return nil, fmt.Errorf("invalid wheel filename %s, expected {name}-{version}(-{build})?-{python}-{abi}-{platform}.whl", filename)
+		}
+		return &PythonFilename{
+			Kind:        Wheel,
+			Name:        m[1],
+			Version:     m[2],
+			BuildTag:    m[3],
+			PythonTag:   m[4],
+			AbiTag:      m[5],
+			PlatformTag: m[6],
+		}, nil
+	}
+
+	if strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".zip") {
+		m := sdistFilename.FindStringSubmatch(filename)
+		if m == nil {
+			return nil, fmt.Errorf("invalid source distribution filename %s, expected {name}-{version}.tar.gz", filename)
+		}
+		return &PythonFilename{
+			Kind:    Sdist,
+			Name:    m[1],
+			Version: m[2],
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported Python distribution %s, expected a .whl, .tar.gz or .zip file", filename)
+}
+
+// Normalize a Python project name as specified by PEP 503
+func NormalizePythonName(name string) string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(name, "-"))
+}
@@ -0,0 +1,65 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestParsePythonFilename(t *testing.T) {
+
+	var tests = []struct {
+		filename string
+		kind     string
+		name     string
+		version  string
+		valid    bool
+	}{
+		{filename: "example_pkg-0.1.0-py3-none-any.whl", kind: Wheel, name: "example_pkg", version: "0.1.0", valid: true},
+		{filename: "numpy-1.26.4-cp312-cp312-manylinux_2_17_x86_64.manylinux2014_x86_64.whl", kind: Wheel, name: "numpy", version: "1.26.4", valid: true},
+		{filename: "pkg-1.0-1build-py3-none-any.whl", kind: Wheel, name: "pkg", version: "1.0", valid: true},
+		{filename: "example-pkg-0.1.0-py3-none-any.whl", valid: false},
+		{filename: "example_pkg-0.1.0.whl", valid: false},
+		{filename: "example-pkg-0.1.0.tar.gz", kind: Sdist, name: "example-pkg", version: "0.1.0", valid: true},
+		{filename: "example_pkg-0.1.0.zip", kind: Sdist, name: "example_pkg", version: "0.1.0", valid: true},
+		{filename: "example_pkg.tar.gz", valid: false},
+		{filename: "example_pkg-0.1.0.egg", valid: false},
+	}
+
+	for _, test := range tests {
+		parsed, err := ParsePythonFilename(test.filename)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%s: expected error", test.filename)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.filename, err)
+			continue
+		}
+		if parsed.Kind != test.kind || parsed.Name != test.name || parsed.Version != test.version {
+			t.Errorf("%s: got %s %s %s", test.filename, parsed.Kind, parsed.Name, parsed.Version)
+		}
+	}
+}
+
+func TestNormalizePythonName(t *testing.T) {
+	for _, name := range []string{"Example_Pkg", "example-pkg", "example.pkg", "EXAMPLE__pkg"} {
+		if normalized := NormalizePythonName(name); normalized != "example-pkg" {
+			t.Errorf("%s: expected example-pkg, got %s", name, normalized)
+		}
+	}
+}
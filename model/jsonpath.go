@@ -0,0 +1,275 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// One selection of a JSONPath: the root, a field, an index or all elements
+type jsonPathStep struct {
+	root  bool
+	all   bool
+	index *int
+	field string
+}
+
+// Text, a path to print or a range repeating its body for the path results
+type jsonPathNode struct {
+	text    string
+	path    []jsonPathStep
+	body    []jsonPathNode
+	isRange bool
+}
+
+// Format the JSON representation of a value with a JSONPath template, the
+// subset of the kubectl dialect that selects values: text with expressions in
+// braces. An expression is a path such as {.name} or {.[*].version}, a quoted
+// string such as {"\n"} or {range .[*]}...{end} to repeat a part for every
+// result of a path. A path starts at the current value with '.' or '[' or at
+// the root with '$' and selects fields with .name or ['name'], elements with
+// [n], negative n counting from the end, and all elements with [*] or .*.
+// Multiple results are separated by spaces, missing ones are left out.
+func FormatJSONPath(o Output, template string) ([]byte, error) {
+	nodes, err := parseJSONPath(template)
+	if err != nil {
+		return nil, err
+	}
+	value, err := jsonValue(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := execJSONPath(&b, nodes, value, value); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Parse a template into nodes, the bodies of ranges are collected on a stack
+// until their {end}
+func parseJSONPath(template string) ([]jsonPathNode, error) {
+	stack := [][]jsonPathNode{nil}
+	var ranges []jsonPathNode
+	add := func(node jsonPathNode) {
+		stack[len(stack)-1] = append(stack[len(stack)-1], node)
+	}
+
+	for template != "" {
+		open := strings.IndexByte(template, '{')
+		if open < 0 {
+			add(jsonPathNode{text: template})
+			break
+		}
+		if open > 0 {
+			add(jsonPathNode{text: template[:open]})
+		}
+		end := actionEnd(template[open+1:])
+		if end < 0 {
+			return nil, fmt.Errorf("invalid jsonpath: unclosed expression %q", template[open:])
+		}
+		expr := strings.TrimSpace(template[open+1 : open+1+end])
+		template = template[open+1+end+1:]
+
+		switch {
+		case expr == "end":
+			if len(ranges) == 0 {
+				return nil, fmt.Errorf("invalid jsonpath: {end} without {range}")
+			}
+			node := ranges[len(ranges)-1]
+			node.body = stack[len(stack)-1]
+			ranges, stack = ranges[:len(ranges)-1], stack[:len(stack)-1]
+			add(node)
+		case strings.HasPrefix(expr, "range "):
+			path, err := parseJSONPathExpr(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			ranges = append(ranges, jsonPathNode{path: path, isRange: true})
+			stack = append(stack, nil)
+		case strings.HasPrefix(expr, `"`):
+			text, err := strconv.Unquote(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jsonpath string %s", expr)
+			}
+			add(jsonPathNode{text: text})
+		default:
+			path, err := parseJSONPathExpr(expr)
+			if err != nil {
+				return nil, err
+			}
+			add(jsonPathNode{path: path})
+		}
+	}
+	if len(ranges) > 0 {
+		return nil, fmt.Errorf("invalid jsonpath: {range} without {end}")
+	}
+	return stack[0], nil
+}
+
+// Offset of the '}' closing an expression, skipping quoted text
+func actionEnd(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote != 0:
+		case s[i] == '"' || s[i] == '\'':
+			quote = s[i]
+		case s[i] == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func parseJSONPathExpr(expr string) ([]jsonPathStep, error) {
+	if expr == "" || !strings.ContainsRune(".[$", rune(expr[0])) {
+		return nil, fmt.Errorf("invalid jsonpath expression %q", expr)
+	}
+
+	path := []jsonPathStep{}
+	rest := expr
+	if rest[0] == '$' {
+		path = append(path, jsonPathStep{root: true})
+		rest = rest[1:]
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			n := strings.IndexAny(rest, ".[")
+			if n < 0 {
+				n = len(rest)
+			}
+			switch field := rest[:n]; field {
+			case "":
+			case "*":
+				path = append(path, jsonPathStep{all: true})
+			default:
+				path = append(path, jsonPathStep{field: field})
+			}
+			rest = rest[n:]
+		case '[':
+			n := strings.IndexByte(rest, ']')
+			if n < 0 {
+				return nil, fmt.Errorf("invalid jsonpath expression %q", expr)
+			}
+			inner := strings.TrimSpace(rest[1:n])
+			switch {
+			case inner == "*":
+				path = append(path, jsonPathStep{all: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, jsonPathStep{field: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid jsonpath index %q in %q", inner, expr)
+				}
+				path = append(path, jsonPathStep{index: &index})
+			}
+			rest = rest[n+1:]
+		default:
+			return nil, fmt.Errorf("invalid jsonpath expression %q", expr)
+		}
+	}
+	return path, nil
+}
+
+// Values selected by a path from the current value
+func evalJSONPath(path []jsonPathStep, root interface{}, current interface{}) []interface{} {
+	values := []interface{}{current}
+	for _, step := range path {
+		var next []interface{}
+		for _, value := range values {
+			switch v := value.(type) {
+			case []interface{}:
+				switch {
+				case step.all:
+					next = append(next, v...)
+				case step.index != nil:
+					index := *step.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			case map[string]interface{}:
+				switch {
+				case step.all:
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				case step.index == nil && !step.root:
+					if field, ok := v[step.field]; ok {
+						next = append(next, field)
+					}
+				}
+			}
+			if step.root {
+				next = append(next, root)
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+func execJSONPath(b *bytes.Buffer, nodes []jsonPathNode, root interface{}, current interface{}) error {
+	for _, node := range nodes {
+		switch {
+		case node.isRange:
+			for _, value := range evalJSONPath(node.path, root, current) {
+				if err := execJSONPath(b, node.body, root, value); err != nil {
+					return err
+				}
+			}
+		case node.path != nil:
+			for i, value := range evalJSONPath(node.path, root, current) {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				if s, ok := value.(string); ok {
+					b.WriteString(s)
+					continue
+				}
+				data, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+				b.Write(data)
+			}
+		default:
+			b.WriteString(node.text)
+		}
+	}
+	return nil
+}
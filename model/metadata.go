@@ -0,0 +1,52 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/textproto"
+)
+
+// The core metadata of a Python distribution, as found in PKG-INFO or METADATA
+type PythonMetadata struct {
+	MetadataVersion string              `json:"metadataVersion"`
+	Name            string              `json:"name"`
+	Version         string              `json:"version"`
+	Summary         string              `json:"summary"`
+	License         string              `json:"license"`
+	RequiresPython  string              `json:"requiresPython"`
+	Fields          map[string][]string `json:"fields"`
+}
+
+// Parse the email header formatted core metadata of a Python distribution.
+// The optional message body holding the long description is ignored.
+func ParsePythonMetadata(r io.Reader) (*PythonMetadata, error) {
+	header, err := textproto.NewReader(bufio.NewReader(r)).ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("could not parse metadata: %v", err)
+	}
+
+	return &PythonMetadata{
+		MetadataVersion: header.Get("Metadata-Version"),
+		Name:            header.Get("Name"),
+		Version:         header.Get("Version"),
+		Summary:         header.Get("Summary"),
+		License:         header.Get("License"),
+		RequiresPython:  header.Get("Requires-Python"),
+		Fields:          header,
+	}, nil
+}
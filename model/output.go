@@ -15,7 +15,16 @@
 package model
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
 )
 
 type Output interface{}
@@ -23,3 +32,135 @@ type Output interface{}
 func FormatJSON(o Output) ([]byte, error) {
 	return json.MarshalIndent(o, "", "  ")
 }
+
+// Format a slice as one compact JSON document per element and line, any
+// other value as a single line
+func FormatJSONLines(o Output) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		err := enc.Encode(o)
+		return b.Bytes(), err
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := enc.Encode(v.Index(i).Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// Header and rows of a Tabular value or of a slice of Tabular values
+func tabulate(o Output, wide bool) ([]string, [][]string, error) {
+	if t, ok := o.(Tabular); ok {
+		return t.Header(wide), [][]string{t.Row(wide)}, nil
+	}
+
+	v := reflect.ValueOf(o)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, nil, fmt.Errorf("cannot print %T as a table", o)
+	}
+
+	elemType := v.Type().Elem()
+	if elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	zero, ok := reflect.New(elemType).Interface().(Tabular)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot print %T as a table", o)
+	}
+
+	rows := make([][]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if (elem.Kind() == reflect.Pointer || elem.Kind() == reflect.Interface) && elem.IsNil() {
+			continue
+		}
+		rows = append(rows, elem.Interface().(Tabular).Row(wide))
+	}
+	return zero.Header(wide), rows, nil
+}
+
+// Format as aligned columns with a header line
+func FormatTable(o Output, wide bool) ([]byte, error) {
+	header, rows, err := tabulate(o, wide)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// keep multi-line values and tabs from breaking the columns
+			cells[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	// empty trailing cells leave padding behind
+	lines := strings.SplitAfter(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(strings.TrimSuffix(line, "\n"), " ")
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// Format as CSV (RFC 4180) with a header record
+func FormatCSV(o Output, wide bool) ([]byte, error) {
+	header, rows, err := tabulate(o, wide)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(header)
+	w.WriteAll(rows)
+	return b.Bytes(), w.Error()
+}
+
+// Generic form of a value as decoded from its JSON representation, so that
+// other formats use the same field names as the JSON output
+func jsonValue(o Output) (interface{}, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	err = json.Unmarshal(data, &value)
+	return value, err
+}
+
+func FormatYAML(o Output) ([]byte, error) {
+	value, err := jsonValue(o)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(value)
+}
+
+// Execute a Go template with the JSON representation of a value as data
+func FormatTemplate(o Output, text string) ([]byte, error) {
+	tmpl, err := template.New("output").Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	value, err := jsonValue(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, value); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
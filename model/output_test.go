@@ -0,0 +1,146 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+)
+
+func testRepositories() []Repository {
+	return []Repository{
+		{Id: 2, Name: "testrepo1", Technology: "R", Published: true, PublicationUri: "http://localhost/repo/testrepo1"},
+		{Id: 3, Name: "testrepo2", Technology: "Python", PublicationUri: "http://localhost/repo/testrepo2"},
+	}
+}
+
+func expectOutput(t *testing.T, expected string, actual []byte, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(actual) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestFormatTable(t *testing.T) {
+	out, err := FormatTable(testRepositories(), false)
+	expectOutput(t, `NAME        TECHNOLOGY   PUBLISHED   PUBLICATION URI
+testrepo1   R            true        http://localhost/repo/testrepo1
+testrepo2   Python       false       http://localhost/repo/testrepo2
+`, out, err)
+
+	out, err = FormatTable([]Repository{}, false)
+	expectOutput(t, "NAME   TECHNOLOGY   PUBLISHED   PUBLICATION URI\n", out, err)
+
+	repos := testRepositories()
+	out, err = FormatTable([]*Repository{nil, &repos[1]}, false)
+	expectOutput(t, `NAME        TECHNOLOGY   PUBLISHED   PUBLICATION URI
+testrepo2   Python       false       http://localhost/repo/testrepo2
+`, out, err)
+
+	out, err = FormatTable(pkg("foo", "1.0"), false)
+	expectOutput(t, `ID   NAME   VERSION   REPOSITORY   TECHNOLOGY   ACTIVE
+0    foo    1.0                                 false
+`, out, err)
+
+	if _, err := FormatTable([]string{"foo"}, false); err == nil {
+		t.Errorf("expected error for values without columns")
+	}
+}
+
+func TestFormatCSV(t *testing.T) {
+	repos := testRepositories()
+	repos[1].LastPublicationTimestamp = "never, so far"
+	out, err := FormatCSV(repos, true)
+	expectOutput(t, `NAME,TECHNOLOGY,PUBLISHED,PUBLICATION URI,ID,DELETED,SYNCHRONIZING,SERVER ADDRESS,LAST PUBLICATION
+testrepo1,R,true,http://localhost/repo/testrepo1,2,false,false,,
+testrepo2,Python,false,http://localhost/repo/testrepo2,3,false,false,,"never, so far"
+`, out, err)
+}
+
+func TestFormatYAML(t *testing.T) {
+	out, err := FormatYAML(pkg("foo", "1.0").Submission)
+	expectOutput(t, "id: 0\nstate: \"\"\n", out, err)
+}
+
+func TestFormatTemplate(t *testing.T) {
+	out, err := FormatTemplate(testRepositories(), `{{range .}}{{.name}}={{.published}}{{"\n"}}{{end}}`)
+	expectOutput(t, "testrepo1=true\ntestrepo2=false\n", out, err)
+
+	if _, err := FormatTemplate(testRepositories(), `{{range .}`); err == nil {
+		t.Errorf("expected error for an invalid template")
+	}
+}
+
+func TestFormatJSONPath(t *testing.T) {
+	var tests = []struct {
+		template string
+		output   string
+	}{
+		{template: `{.[*].name}`, output: "testrepo1 testrepo2"},
+		{template: `{[*].name}`, output: "testrepo1 testrepo2"},
+		{template: `{$[0].id}`, output: "2"},
+		{template: `{.[-1]['technology']}`, output: "Python"},
+		{template: `{.*.name}`, output: "testrepo1 testrepo2"},
+		{template: `{range .[*]}{.name}{"\t"}{.published}{"\n"}{end}`, output: "testrepo1\ttrue\ntestrepo2\tfalse\n"},
+		{template: `{range [*]}{range .name}[{.}]{end}{end}`, output: "[testrepo1][testrepo2]"},
+		{template: `{"{}"}`, output: "{}"},
+		{template: `{[5].name}{[*].missing}`, output: ""},
+		// expressions the kubectl implementation panicked on
+		{template: `names: {range .[*]}{$[0].name}/{.name} {end}`, output: "names: testrepo1/testrepo1 testrepo1/testrepo2 "},
+		{template: `{range [*]}{$[0].name}{end}`, output: "testrepo1testrepo1"},
+	}
+
+	for _, test := range tests {
+		out, err := FormatJSONPath(testRepositories(), test.template)
+		expectOutput(t, test.output, out, err)
+	}
+
+	for _, template := range []string{`{.name`, `{range .[*]}{.name}`, `{.name}{end}`, `{name}`, `{.[x]}`, `{[?(@.published==true)].name}`} {
+		if _, err := FormatJSONPath(testRepositories(), template); err == nil {
+			t.Errorf("expected error for %s", template)
+		}
+	}
+}
+
+func TestFormatDetails(t *testing.T) {
+	version, _ := CanonicalVersion("1.0-2")
+	pkg := RPackage{
+		Package: Package{
+			Id:         7,
+			Name:       "foo",
+			Version:    *version,
+			Repository: Repository{Name: "testrepo1"},
+			Technology: "R",
+			Active:     true,
+			Links:      []Link{{Rel: "self", Href: "http://localhost/api/v2/manager/r/packages/7"}},
+		},
+		Depends: "R (>= 4.0),\nmethods",
+		License: "GPL-3",
+	}
+	expectOutput(t, `ID:           7
+Name:         foo
+Version:      1.0-2
+Repository:   testrepo1
+Technology:   R
+Active:       true
+Deleted:      false
+License:      GPL-3
+Depends:      R (>= 4.0),
+              methods
+Links:        self: http://localhost/api/v2/manager/r/packages/7
+`, FormatDetails(pkg), nil)
+}
@@ -36,6 +36,21 @@ func (r *Response[C]) Unmarshal(data []byte) error {
 	return nil
 }
 
+type EntityResponse[C any] struct {
+	Status      string `json:"status"`
+	Code        int    `json:"code"`
+	Message     string `json:"message"`
+	MessageCode string `json:"messageCode"`
+	Data        C      `json:"data"`
+}
+
+func (r *EntityResponse[C]) Unmarshal(data []byte) error {
+	if err := json.Unmarshal(data, r); err != nil {
+		return fmt.Errorf("could not unpack response: %s", err)
+	}
+	return nil
+}
+
 type Data[C any] struct {
 	Links   []Link `json:"links"`
 	Content []C    `json:"content"`
@@ -105,16 +120,42 @@ type User struct {
 }
 
 type Repository struct {
-	Id             int    `json:"id"`
-	Name           string `json:"name"`
-	PublicationUri string `json:"publicationUri"`
-	Published      bool   `json:"published"`
-	Technology     string `json:"technology"`
+	Id                        int    `json:"id"`
+	Version                   int    `json:"version"`
+	Name                      string `json:"name"`
+	PublicationUri            string `json:"publicationUri"`
+	ServerAddress             string `json:"serverAddress"`
+	Published                 bool   `json:"published"`
+	Deleted                   bool   `json:"deleted"`
+	Synchronizing             bool   `json:"synchronizing"`
+	Technology                string `json:"technology"`
+	HashMethod                string `json:"hashMethod,omitempty"`
+	RedirectToSource          bool   `json:"redirectToSource,omitempty"`
+	RequiresAuthentication    bool   `json:"requiresAuthentication"`
+	LastPublicationSuccessful bool   `json:"lastPublicationSuccessful"`
+	LastPublicationTimestamp  string `json:"lastPublicationTimestamp"`
+	LastModifiedTimestamp     string `json:"lastModifiedTimestamp"`
+	Links                     []Link `json:"links,omitempty"`
 }
 
+const (
+	SubmissionWaiting   = "WAITING"
+	SubmissionAccepted  = "ACCEPTED"
+	SubmissionRejected  = "REJECTED"
+	SubmissionCancelled = "CANCELLED"
+)
+
 type Submission struct {
-	Id    int    `json:"id"`
-	State string `json:"state"`
+	Id           int      `json:"id"`
+	State        string   `json:"state"`
+	Technology   string   `json:"technology,omitempty"`
+	Package      *Package `json:"package,omitempty"`
+	Submitter    *User    `json:"submitter,omitempty"`
+	Approver     *User    `json:"approver,omitempty"`
+	Changes      string   `json:"changes,omitempty"`
+	RejectReason string   `json:"rejectReason,omitempty"`
+	Created      string   `json:"created,omitempty"`
+	Links        []Link   `json:"links,omitempty"`
 }
 
 type Link struct {
@@ -129,17 +170,27 @@ type Link struct {
 	Name        string `json:"name"`
 }
 
-type GenericPackage interface {
+type Named interface {
 	GetName() string
+}
+
+type GenericPackage interface {
+	Named
 	GetVersion() Version
 	Summary() string
 	GetId() int
+	GetPackage() Package
 }
 
 func (p Package) GetName() string {
 	return p.Name
 }
 
+// The fields common to packages of all technologies
+func (p Package) GetPackage() Package {
+	return p
+}
+
 func (p Package) GetVersion() Version {
 	return p.Version
 }
@@ -152,16 +203,31 @@ func (pkg Package) Summary() string {
 	return fmt.Sprintf("%s %s", pkg.Name, pkg.Version.CanonicalRep)
 }
 
-// Filter packages matching a name glob pattern
-func FilterByName[G GenericPackage](packages []G, name string) ([]G, error) {
-	filtered := make([]G, 0)
+func (r Repository) GetName() string {
+	return r.Name
+}
 
-	for _, pkg := range packages {
-		matched, err := filepath.Match(name, pkg.GetName())
+func (r Repository) Summary() string {
+	return fmt.Sprintf("%s (%s)", r.Name, r.Technology)
+}
+
+func (s Submission) Summary() string {
+	if s.Package == nil {
+		return fmt.Sprintf("submission %d", s.Id)
+	}
+	return fmt.Sprintf("submission %d (%s)", s.Id, s.Package.Summary())
+}
+
+// Filter packages or repositories matching a name glob pattern
+func FilterByName[N Named](items []N, name string) ([]N, error) {
+	filtered := make([]N, 0)
+
+	for _, item := range items {
+		matched, err := filepath.Match(name, item.GetName())
 		if err != nil {
 			return nil, err
 		} else if matched {
-			filtered = append(filtered, pkg)
+			filtered = append(filtered, item)
 		}
 	}
 
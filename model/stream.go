@@ -0,0 +1,95 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"path/filepath"
+)
+
+// A sequence of pairs, shaped like iter.Seq2 so that it can be ranged over
+// with Go 1.23 and later. A Seq2[T, error] yields items until the first error.
+type Seq2[K, V any] func(yield func(K, V) bool)
+
+// Collect the items of a sequence, stopping at the first error
+func Collect[T any](seq Seq2[T, error]) ([]T, error) {
+	items := make([]T, 0)
+	var err error
+	seq(func(item T, e error) bool {
+		if e != nil {
+			err = e
+			return false
+		}
+		items = append(items, item)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Stream the items for which keep returns true
+func FilterSeq[T any](seq Seq2[T, error], keep func(T) bool) Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		seq(func(item T, err error) bool {
+			if err != nil {
+				return yield(item, err)
+			}
+			if !keep(item) {
+				return true
+			}
+			return yield(item, nil)
+		})
+	}
+}
+
+// Stream the items matching a name glob pattern, see FilterByName
+func FilterSeqByName[N Named](seq Seq2[N, error], name string) Seq2[N, error] {
+	return func(yield func(N, error) bool) {
+		var zero N
+		if _, err := filepath.Match(name, ""); err != nil {
+			yield(zero, err)
+			return
+		}
+		seq(func(item N, err error) bool {
+			if err != nil {
+				return yield(zero, err)
+			}
+			if matched, _ := filepath.Match(name, item.GetName()); !matched {
+				return true
+			}
+			return yield(item, nil)
+		})
+	}
+}
+
+// Stream archived packages, see FilterArchived. A package is only known not
+// to be the newest version once all packages are read, so the sequence is
+// consumed completely before the first package is yielded.
+func FilterSeqArchived[G GenericPackage](seq Seq2[G, error]) Seq2[G, error] {
+	return func(yield func(G, error) bool) {
+		packages, err := Collect(seq)
+		if err != nil {
+			var zero G
+			yield(zero, err)
+			return
+		}
+		for _, pkg := range FilterArchived(packages) {
+			if !yield(pkg, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func seqOf(pkgs []Package, err error) Seq2[Package, error] {
+	return func(yield func(Package, error) bool) {
+		for _, pkg := range pkgs {
+			if !yield(pkg, nil) {
+				return
+			}
+		}
+		if err != nil {
+			yield(Package{}, err)
+		}
+	}
+}
+
+func TestFilterSeqByName(t *testing.T) {
+
+	pkgs := []Package{
+		pkg("foo", "1.0"),
+		pkg("bar", "0.0.1"),
+		pkg("foobar", "0.9"),
+	}
+	filtered, err := Collect(FilterSeqByName(seqOf(pkgs, nil), "foo*"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(filtered) != 2 {
+		t.Errorf("expected length: 2, got %d", len(filtered))
+	}
+	expectFiltered(t, filtered, pkgs[1])
+
+	if _, err := Collect(FilterSeqByName(seqOf(pkgs, nil), "[")); err == nil {
+		t.Errorf("expected error for a malformed pattern")
+	}
+
+	failure := errors.New("page failed")
+	if _, err := Collect(FilterSeqByName(seqOf(pkgs, failure), "*")); err != failure {
+		t.Errorf("expected error %s, got %v", failure, err)
+	}
+
+	var seen int
+	FilterSeqByName(seqOf(pkgs, nil), "*")(func(pkg Package, err error) bool {
+		seen++
+		return false
+	})
+	if seen != 1 {
+		t.Errorf("expected iteration to stop after 1 package, got %d", seen)
+	}
+}
+
+func TestFilterSeqArchived(t *testing.T) {
+
+	pkgs := []Package{
+		pkg("foo", "1.0"),
+		pkg("foo", "0.9"),
+		pkg("bar", "0.0.1"),
+	}
+	filtered, err := Collect(FilterSeqArchived(seqOf(pkgs, nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(filtered) != 1 {
+		t.Errorf("expected length: 1, got %d", len(filtered))
+	}
+	expectFiltered(t, filtered, pkgs[0])
+	expectFiltered(t, filtered, pkgs[2])
+}
@@ -0,0 +1,132 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strconv"
+)
+
+// A value that can be printed as a row of table or CSV output. Wide output
+// adds columns to the default ones.
+type Tabular interface {
+	Header(wide bool) []string
+	Row(wide bool) []string
+}
+
+func (pkg Package) Header(wide bool) []string {
+	header := []string{"ID", "NAME", "VERSION", "REPOSITORY", "TECHNOLOGY", "ACTIVE"}
+	if wide {
+		header = append(header, "DELETED", "SUBMISSION", "MAINTAINER", "TITLE")
+	}
+	return header
+}
+
+func (pkg Package) Row(wide bool) []string {
+	row := []string{
+		strconv.Itoa(pkg.Id),
+		pkg.Name,
+		pkg.Version.CanonicalRep,
+		pkg.Repository.Name,
+		pkg.Technology,
+		strconv.FormatBool(pkg.Active),
+	}
+	if wide {
+		row = append(row, strconv.FormatBool(pkg.Deleted), pkg.Submission.State, pkg.User.Login, pkg.Title)
+	}
+	return row
+}
+
+func (pkg RPackage) Header(wide bool) []string {
+	header := pkg.Package.Header(wide)
+	if wide {
+		header = append(header, "LICENSE", "MD5SUM")
+	}
+	return header
+}
+
+func (pkg RPackage) Row(wide bool) []string {
+	row := pkg.Package.Row(wide)
+	if wide {
+		row = append(row, pkg.License, pkg.Md5sum)
+	}
+	return row
+}
+
+func (pkg PythonPackage) Header(wide bool) []string {
+	header := pkg.Package.Header(wide)
+	if wide {
+		header = append(header, "LICENSE", "REQUIRES PYTHON", "HASH")
+	}
+	return header
+}
+
+func (pkg PythonPackage) Row(wide bool) []string {
+	row := pkg.Package.Row(wide)
+	if wide {
+		row = append(row, pkg.License, pkg.RequiresPython, pkg.Hash)
+	}
+	return row
+}
+
+func (r Repository) Header(wide bool) []string {
+	header := []string{"NAME", "TECHNOLOGY", "PUBLISHED", "PUBLICATION URI"}
+	if wide {
+		header = append(header, "ID", "DELETED", "SYNCHRONIZING", "SERVER ADDRESS", "LAST PUBLICATION")
+	}
+	return header
+}
+
+func (r Repository) Row(wide bool) []string {
+	row := []string{r.Name, r.Technology, strconv.FormatBool(r.Published), r.PublicationUri}
+	if wide {
+		row = append(row,
+			strconv.Itoa(r.Id),
+			strconv.FormatBool(r.Deleted),
+			strconv.FormatBool(r.Synchronizing),
+			r.ServerAddress,
+			r.LastPublicationTimestamp,
+		)
+	}
+	return row
+}
+
+func (s Submission) Header(wide bool) []string {
+	header := []string{"ID", "STATE", "PACKAGE", "VERSION", "REPOSITORY", "SUBMITTER"}
+	if wide {
+		header = append(header, "APPROVER", "CREATED", "REJECT REASON")
+	}
+	return header
+}
+
+func (s Submission) Row(wide bool) []string {
+	var name, version, repository, submitter string
+	if s.Package != nil {
+		name = s.Package.Name
+		version = s.Package.Version.CanonicalRep
+		repository = s.Package.Repository.Name
+	}
+	if s.Submitter != nil {
+		submitter = s.Submitter.Login
+	}
+	row := []string{strconv.Itoa(s.Id), s.State, name, version, repository, submitter}
+	if wide {
+		var approver string
+		if s.Approver != nil {
+			approver = s.Approver.Login
+		}
+		row = append(row, approver, s.Created, s.RejectReason)
+	}
+	return row
+}
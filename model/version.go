@@ -17,6 +17,7 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -181,3 +182,68 @@ func (x Version) Less(y Version) bool {
 type PythonVersion struct {
 	CanonicalRep string
 }
+
+// Version scheme of PEP 440, including the permitted alternative spellings.
+// Groups: epoch, release, pre-release label and number, implicit post-release
+// number, post-release label and number, dev label and number, local version.
+var pep440Version = regexp.MustCompile(`(?i)^v?(?:([0-9]+)!)?([0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?([0-9]*))?` +
+	`(?:-([0-9]+)|[-_.]?(post|rev|r)[-_.]?([0-9]*))?` +
+	`(?:[-_.]?(dev)[-_.]?([0-9]*))?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+// Parse a Python version, which must be valid according to PEP 440
+func ParsePythonVersion(rep string) (*PythonVersion, error) {
+	if !pep440Version.MatchString(strings.TrimSpace(rep)) {
+		return nil, fmt.Errorf("invalid PEP 440 version %s", rep)
+	}
+	return &PythonVersion{CanonicalRep: strings.TrimSpace(rep)}, nil
+}
+
+var preReleaseLabels = map[string]string{
+	"a": "a", "alpha": "a", "b": "b", "beta": "b", "c": "rc", "rc": "rc", "pre": "rc", "preview": "rc",
+}
+
+// Normal form of a Python version according to PEP 440, without the trailing
+// zeros of the release like packaging's canonicalize_version, so that equal
+// versions such as 1.0 and 1.0.0 or 1.0rc1 and 1.0-RC.1 have the same form
+func NormalizePythonVersion(rep string) (string, error) {
+	m := pep440Version.FindStringSubmatch(strings.TrimSpace(rep))
+	if m == nil {
+		return "", fmt.Errorf("invalid PEP 440 version %s", rep)
+	}
+	number := func(s string) string {
+		n, _ := strconv.Atoi(s)
+		return strconv.Itoa(n)
+	}
+
+	var b strings.Builder
+	if m[1] != "" && number(m[1]) != "0" {
+		b.WriteString(number(m[1]) + "!")
+	}
+	release := strings.Split(m[2], ".")
+	for len(release) > 1 && number(release[len(release)-1]) == "0" {
+		release = release[:len(release)-1]
+	}
+	for i, part := range release {
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(number(part))
+	}
+	if m[3] != "" {
+		b.WriteString(preReleaseLabels[strings.ToLower(m[3])] + number(m[4]))
+	}
+	if m[5] != "" {
+		b.WriteString(".post" + number(m[5]))
+	} else if m[6] != "" {
+		b.WriteString(".post" + number(m[7]))
+	}
+	if m[8] != "" {
+		b.WriteString(".dev" + number(m[9]))
+	}
+	if m[10] != "" {
+		b.WriteString("+" + strings.ToLower(strings.NewReplacer("-", ".", "_", ".").Replace(m[10])))
+	}
+	return b.String(), nil
+}
@@ -101,3 +101,56 @@ func TestLess(t *testing.T) {
 	}
 
 }
+
+func TestParsePythonVersion(t *testing.T) {
+	valid := []string{"1.0", "1.0.0", "2!1.0", "1.0a1", "1.0b2", "1.0rc1", "1.0.post1", "1.0.dev3", "1.0a1.post2.dev3", "1.0+local.7", "v1.0", "1.0-1"}
+	for _, v := range valid {
+		if _, err := ParsePythonVersion(v); err != nil {
+			t.Errorf("expected %s to be valid: %s", v, err)
+		}
+	}
+
+	invalid := []string{"", "alpha", "1.0-beta-two", "1.0+", "1..0", "1.0 final"}
+	for _, v := range invalid {
+		if _, err := ParsePythonVersion(v); err == nil {
+			t.Errorf("expected %s to be invalid", v)
+		}
+	}
+}
+
+func TestNormalizePythonVersion(t *testing.T) {
+	var tests = []struct {
+		rep        string
+		normalized string
+	}{
+		{"1.0", "1"},
+		{"1.0.0", "1"},
+		{"1.10.0", "1.10"},
+		{"v01.02", "1.2"},
+		{"0!1.0", "1"},
+		{"2!1.0", "2!1"},
+		{"1.0rc1", "1rc1"},
+		{"1.0-RC.1", "1rc1"},
+		{"1.0alpha", "1a0"},
+		{"1.0.preview2", "1rc2"},
+		{"1.0-1", "1.post1"},
+		{"1.0_rev_2", "1.post2"},
+		{"1.0.post", "1.post0"},
+		{"1.0-dev", "1.dev0"},
+		{"1.0a1.post2.dev3", "1a1.post2.dev3"},
+		{"1.0+Local_7", "1+local.7"},
+	}
+
+	for _, test := range tests {
+		normalized, err := NormalizePythonVersion(test.rep)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.rep, err)
+		} else if normalized != test.normalized {
+			t.Errorf("%s: expected %s, got %s", test.rep, test.normalized, normalized)
+		}
+	}
+
+	if _, err := NormalizePythonVersion("1.0 final"); err == nil {
+		t.Errorf("expected error for an invalid version")
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+var supportedMetadataVersions = map[string]bool{
+	"1.0": true, "1.1": true, "1.2": true, "2.0": true, "2.1": true, "2.2": true, "2.3": true, "2.4": true,
+}
+
+// Validate a Python wheel or source distribution
+func PythonPackage(archive string) Result {
+	result := Result{File: archive}
+
+	filename, err := model.ParsePythonFilename(filepath.Base(archive))
+	if err != nil {
+		result.errorf("%v", err)
+		return result
+	}
+
+	var meta *model.PythonMetadata
+	if filename.Kind == model.Wheel {
+		meta, err = readWheel(archive, filename, &result)
+	} else {
+		meta, err = readSdist(archive, filename, &result)
+	}
+	if err != nil {
+		result.errorf("could not read archive: %v", err)
+		return result
+	}
+	if meta == nil {
+		return result
+	}
+
+	if meta.MetadataVersion == "" {
+		result.errorf("metadata is missing mandatory field Metadata-Version")
+	} else if !supportedMetadataVersions[meta.MetadataVersion] {
+		result.warnf("unknown metadata version %s", meta.MetadataVersion)
+	}
+	if meta.Name == "" {
+		result.errorf("metadata is missing mandatory field Name")
+	} else if model.NormalizePythonName(meta.Name) != model.NormalizePythonName(filename.Name) {
+		result.errorf("name %s does not match archive name %s", meta.Name, filename.Name)
+	}
+	if meta.Version == "" {
+		result.errorf("metadata is missing mandatory field Version")
+	} else {
+		version, err := model.NormalizePythonVersion(meta.Version)
+		if err != nil {
+			result.errorf("%v", err)
+		} else if archiveVersion, err := model.NormalizePythonVersion(filename.Version); err != nil || archiveVersion != version {
+			result.errorf("version %s does not match archive version %s", meta.Version, filename.Version)
+		}
+	}
+	if meta.Summary == "" {
+		result.warnf("metadata has no Summary")
+	}
+
+	return result
+}
+
+// Read METADATA from the .dist-info directory of a wheel. The directory is
+// looked up rather than derived from the file name, whose name and version
+// may be spelled differently.
+func readWheel(archive string, filename *model.PythonFilename, result *Result) (*model.PythonMetadata, error) {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var distInfos []string
+	seen := make(map[string]bool)
+	metadata := make(map[string]*zip.File)
+	wheel := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		dir, file, ok := strings.Cut(f.Name, "/")
+		if !ok || !strings.HasSuffix(dir, ".dist-info") {
+			continue
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			distInfos = append(distInfos, dir)
+		}
+		switch file {
+		case "METADATA":
+			metadata[dir] = f
+		case "WHEEL":
+			wheel[dir] = f
+		}
+	}
+	if len(distInfos) != 1 {
+		result.errorf("wheel must contain exactly one .dist-info directory, found %d", len(distInfos))
+		return nil, nil
+	}
+
+	distInfo := distInfos[0]
+	if wheel[distInfo] == nil {
+		result.errorf("wheel does not contain %s/WHEEL", distInfo)
+	}
+	if metadata[distInfo] == nil {
+		result.errorf("wheel does not contain %s/METADATA", distInfo)
+		return nil, nil
+	}
+
+	r, err := metadata[distInfo].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return model.ParsePythonMetadata(r)
+}
+
+// Read PKG-INFO from the single top-level directory of a source distribution
+func readSdist(archive string, filename *model.PythonFilename, result *Result) (*model.PythonMetadata, error) {
+	var meta *model.PythonMetadata
+	var topLevel []string
+	seen := make(map[string]bool)
+
+	visit := func(name string, open func() (io.ReadCloser, error)) error {
+		name = strings.TrimPrefix(path.Clean(name), "./")
+		if name == "." {
+			return nil
+		}
+		top, rest, _ := strings.Cut(name, "/")
+		if !seen[top] {
+			seen[top] = true
+			topLevel = append(topLevel, top)
+		}
+		if rest != "PKG-INFO" || meta != nil {
+			return nil
+		}
+		r, err := open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		meta, err = model.ParsePythonMetadata(r)
+		return err
+	}
+
+	if strings.HasSuffix(archive, ".zip") {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if err := visit(f.Name, f.Open); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		f, err := os.Open(archive)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		tr := tar.NewReader(gz)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeDir {
+				continue
+			}
+			if err := visit(hdr.Name, func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	expected := filename.Name + "-" + filename.Version
+	if len(topLevel) != 1 {
+		result.errorf("archive must contain exactly one top-level directory, found %d", len(topLevel))
+	} else if topLevel[0] != expected {
+		result.errorf("top-level directory %s does not match %s", topLevel[0], expected)
+	}
+	if meta == nil {
+		result.errorf("source distribution does not contain %s/PKG-INFO", expected)
+	}
+	return meta, nil
+}
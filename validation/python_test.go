@@ -0,0 +1,153 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const examplePkgMetadata = `Metadata-Version: 2.1
+Name: example-pkg
+Version: 0.1.0
+Summary: An example package
+License: MIT
+
+A longer description.
+`
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPythonPackage(t *testing.T) {
+	dir := t.TempDir()
+
+	var tests = []struct {
+		filename string
+		files    map[string]string
+		errors   int
+		warnings int
+	}{
+		{
+			filename: "example_pkg-0.1.0-py3-none-any.whl",
+			files: map[string]string{
+				"example_pkg/__init__.py":                   "",
+				"example_pkg-0.1.0.dist-info/METADATA":      examplePkgMetadata,
+				"example_pkg-0.1.0.dist-info/WHEEL":         "Wheel-Version: 1.0\n",
+				"example_pkg-0.1.0.dist-info/RECORD":        "",
+				"example_pkg-0.1.0.dist-info/top_level.txt": "example_pkg\n",
+			},
+		},
+		{
+			filename: "example_pkg-0.1.0-py3-none-any.whl",
+			files: map[string]string{
+				"example_pkg-0.1.0.dist-info/METADATA": strings.Replace(examplePkgMetadata, "Version: 0.1.0", "Version: 0.2.0", 1),
+				"example_pkg-0.1.0.dist-info/WHEEL":    "Wheel-Version: 1.0\n",
+			},
+			errors: 1,
+		},
+		{
+			filename: "example_pkg-0.1.0-py3-none-any.whl",
+			files:    map[string]string{"example_pkg/__init__.py": ""},
+			errors:   1,
+		},
+		{
+			filename: "example_pkg-0.1.0-py3-none-any.whl",
+			files: map[string]string{
+				"Example.Pkg-0.1.dist-info/METADATA": strings.Replace(examplePkgMetadata, "Version: 0.1.0", "Version: 0.1", 1),
+				"Example.Pkg-0.1.dist-info/WHEEL":    "Wheel-Version: 1.0\n",
+			},
+		},
+		{
+			filename: "example_pkg-1.0rc1-py3-none-any.whl",
+			files: map[string]string{
+				"example_pkg-1.0rc1.dist-info/METADATA": strings.Replace(examplePkgMetadata, "Version: 0.1.0", "Version: 1.0-RC.1", 1),
+				"example_pkg-1.0rc1.dist-info/WHEEL":    "Wheel-Version: 1.0\n",
+			},
+		},
+		{
+			filename: "example-pkg-0.1.0.zip",
+			files: map[string]string{
+				"example-pkg-0.1.0/PKG-INFO": strings.Replace(examplePkgMetadata, "Summary: An example package\n", "", 1),
+				"example-pkg-0.1.0/setup.py": "",
+			},
+			warnings: 1,
+		},
+		{
+			filename: "example-pkg-0.1.0.zip",
+			files: map[string]string{
+				"example-pkg-0.1.0/PKG-INFO": strings.Replace(examplePkgMetadata, "Name: example-pkg", "Name: other-pkg", 1),
+				"other/setup.py":             "",
+			},
+			errors: 2,
+		},
+		{
+			filename: "example_pkg.whl",
+			files:    map[string]string{},
+			errors:   1,
+		},
+	}
+
+	for i, test := range tests {
+		sub := filepath.Join(dir, strings.Repeat("x", i+1))
+		if err := os.Mkdir(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		archive := filepath.Join(sub, test.filename)
+		writeZip(t, archive, test.files)
+
+		result := PythonPackage(archive)
+		if len(result.Errors) != test.errors {
+			t.Errorf("%d: expected %d errors, got %v", i, test.errors, result.Errors)
+		}
+		if len(result.Warnings) != test.warnings {
+			t.Errorf("%d: expected %d warnings, got %v", i, test.warnings, result.Warnings)
+		}
+	}
+}
+
+func TestPythonSdist(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "example-pkg-0.1.0.tar.gz")
+	writeTarGz(t, archive, map[string]string{
+		"example-pkg-0.1.0/PKG-INFO":                      examplePkgMetadata,
+		"example-pkg-0.1.0/example_pkg.egg-info/PKG-INFO": examplePkgMetadata,
+		"example-pkg-0.1.0/example_pkg/__init__.py":       "",
+	})
+
+	if result := PythonPackage(archive); !result.Valid() || len(result.Warnings) != 0 {
+		t.Errorf("expected valid sdist, got %v %v", result.Errors, result.Warnings)
+	}
+}
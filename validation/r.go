@@ -0,0 +1,126 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"openanalytics.eu/rdepot/cli/model"
+)
+
+var mandatoryDescriptionFields = []string{"Package", "Version", "Title", "Description", "License"}
+
+// Validate an R source package archive as produced by R CMD build
+func RPackage(archive string) Result {
+	result := Result{File: archive}
+
+	desc, topLevel, err := readRArchive(archive)
+	if err != nil {
+		result.errorf("could not read archive: %v", err)
+		return result
+	}
+
+	if len(topLevel) != 1 {
+		result.errorf("archive must contain exactly one top-level directory, found %d", len(topLevel))
+	}
+	if desc == nil {
+		result.errorf("archive does not contain a DESCRIPTION file")
+		return result
+	}
+
+	for _, field := range mandatoryDescriptionFields {
+		if value, _ := desc.Get(field); value == "" {
+			result.errorf("DESCRIPTION is missing mandatory field %s", field)
+		}
+	}
+	if _, ok := desc.Get("Authors@R"); !ok && (desc.Author == "" || desc.Maintainer == "") {
+		result.errorf("DESCRIPTION must contain Authors@R or both Author and Maintainer")
+	}
+	if _, ok := desc.Get("Built"); ok {
+		result.errorf("archive is a binary package, expected a source package")
+	}
+	if _, ok := desc.Get("Packaged"); !ok {
+		result.warnf("DESCRIPTION has no Packaged field, was the archive built with R CMD build?")
+	}
+
+	if desc.Version != "" {
+		if _, err := model.CanonicalVersion(desc.Version); err != nil {
+			result.errorf("invalid version %s: %v", desc.Version, err)
+		}
+	}
+
+	if desc.Package != "" {
+		if len(topLevel) == 1 && topLevel[0] != desc.Package {
+			result.errorf("top-level directory %s does not match package name %s", topLevel[0], desc.Package)
+		}
+		if expected := desc.Package + "_" + desc.Version + ".tar.gz"; filepath.Base(archive) != expected {
+			result.errorf("archive name %s does not match %s", filepath.Base(archive), expected)
+		}
+	}
+
+	return result
+}
+
+// Read the DESCRIPTION and the top-level directories of an R package archive
+func readRArchive(archive string) (*model.Description, []string, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gz.Close()
+
+	var desc *model.Description
+	var topLevel []string
+	seen := make(map[string]bool)
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+		if name == "." {
+			continue
+		}
+		top, rest, _ := strings.Cut(name, "/")
+		if !seen[top] {
+			seen[top] = true
+			topLevel = append(topLevel, top)
+		}
+
+		if rest == "DESCRIPTION" && hdr.Typeflag == tar.TypeReg && desc == nil {
+			if desc, err = model.ParseDescription(tr); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return desc, topLevel, nil
+}
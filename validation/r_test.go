@@ -0,0 +1,127 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const oaColorsDescription = `Package: oaColors
+Type: Package
+Title: OpenAnalytics Colors Package
+Version: 0.0.4
+Author: Jason Waddell
+Maintainer: Jason Waddell <jason.waddell@openanalytics.eu>
+Description: Provides carefully chosen color palettes as used a.o. at OpenAnalytics.
+License: GPL-3 + file LICENSE
+Packaged: 2017-10-03 06:33:18 UTC; tverbeke
+`
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRPackage(t *testing.T) {
+	dir := t.TempDir()
+
+	var tests = []struct {
+		filename string
+		files    map[string]string
+		errors   int
+		warnings int
+	}{
+		{
+			filename: "oaColors_0.0.4.tar.gz",
+			files:    map[string]string{"oaColors/DESCRIPTION": oaColorsDescription, "oaColors/NAMESPACE": ""},
+		},
+		{
+			filename: "oaColors_0.0.5.tar.gz",
+			files:    map[string]string{"oaColors/DESCRIPTION": oaColorsDescription},
+			errors:   1,
+		},
+		{
+			filename: "oaColors_0.0.4.tar.gz",
+			files:    map[string]string{"oaColors/DESCRIPTION": oaColorsDescription, "extra/file": ""},
+			errors:   1,
+		},
+		{
+			filename: "oaColors_0.0.4.tar.gz",
+			files:    map[string]string{"oaColors/DESCRIPTION": strings.Replace(oaColorsDescription, "Packaged", "Built", 1)},
+			errors:   1,
+			warnings: 1,
+		},
+		{
+			filename: "oaColors_0.0.4.tar.gz",
+			files:    map[string]string{"oaColors/DESCRIPTION": strings.Replace(oaColorsDescription, "License: GPL-3 + file LICENSE\n", "", 1)},
+			errors:   1,
+		},
+		{
+			filename: "oaColors_alpha.tar.gz",
+			files:    map[string]string{"oaColors/DESCRIPTION": strings.Replace(oaColorsDescription, "0.0.4", "alpha", 1)},
+			errors:   1,
+		},
+		{
+			filename: "oaColors_0.0.4.tar.gz",
+			files:    map[string]string{"oaColors/NAMESPACE": ""},
+			errors:   1,
+		},
+	}
+
+	for i, test := range tests {
+		sub := filepath.Join(dir, strings.Repeat("x", i+1))
+		if err := os.Mkdir(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		archive := filepath.Join(sub, test.filename)
+		writeTarGz(t, archive, test.files)
+
+		result := RPackage(archive)
+		if len(result.Errors) != test.errors {
+			t.Errorf("%d: expected %d errors, got %v", i, test.errors, result.Errors)
+		}
+		if len(result.Warnings) != test.warnings {
+			t.Errorf("%d: expected %d warnings, got %v", i, test.warnings, result.Warnings)
+		}
+		if result.Valid() != (result.Err() == nil) {
+			t.Errorf("%d: Valid and Err disagree", i)
+		}
+	}
+}
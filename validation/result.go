@@ -0,0 +1,69 @@
+// Copyright 2020-2024 Open Analytics
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Outcome of validating a package archive before submission
+type Result struct {
+	File     string   `json:"file"`
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+func (r *Result) errorf(format string, a ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, a...))
+}
+
+func (r *Result) warnf(format string, a ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, a...))
+}
+
+func (r Result) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Combine all validation errors into a single error, nil if the archive is valid
+func (r Result) Err() error {
+	if r.Valid() {
+		return nil
+	}
+	errs := make([]error, 0, len(r.Errors))
+	for _, e := range r.Errors {
+		errs = append(errs, errors.New(e))
+	}
+	return fmt.Errorf("%s is invalid: %w", r.File, errors.Join(errs...))
+}
+
+func (r Result) Header(wide bool) []string {
+	header := []string{"FILE", "VALID", "ERRORS", "WARNINGS"}
+	if wide {
+		header = append(header, "MESSAGES")
+	}
+	return header
+}
+
+func (r Result) Row(wide bool) []string {
+	row := []string{r.File, strconv.FormatBool(r.Valid()), strconv.Itoa(len(r.Errors)), strconv.Itoa(len(r.Warnings))}
+	if wide {
+		row = append(row, strings.Join(append(append([]string{}, r.Errors...), r.Warnings...), "; "))
+	}
+	return row
+}